@@ -0,0 +1,130 @@
+// Package apperror describes failures by kind so callers can react to them,
+// and the HTTP layer can pick a status code, without comparing messages.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"oms/i18n"
+	"time"
+)
+
+// Kinds of failure. Match them with errors.Is; an *Error of that kind
+// matches, however deeply it is wrapped.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrForbidden       = errors.New("forbidden")
+	ErrValidation      = errors.New("validation failed")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrLocked          = errors.New("locked")
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+// Error is a failure of a known kind. Its message is written for clients;
+// the wrapped cause is for logs only. Wrap and the With methods return a
+// copy, so an Error can be declared once and shared.
+type Error struct {
+	kind       error
+	format     string
+	args       []any
+	cause      error
+	details    any
+	retryAfter time.Duration
+}
+
+func newError(kind error, format string, args ...any) *Error {
+	return &Error{kind: kind, format: format, args: args}
+}
+
+// NotFound reports a missing record
+func NotFound(format string, args ...any) *Error {
+	return newError(ErrNotFound, format, args...)
+}
+
+// Conflict reports a clash with existing state, such as a duplicate name
+func Conflict(format string, args ...any) *Error {
+	return newError(ErrConflict, format, args...)
+}
+
+// Forbidden reports a caller who is known but not allowed to do this
+func Forbidden(format string, args ...any) *Error {
+	return newError(ErrForbidden, format, args...)
+}
+
+// Validation reports input the request cannot be carried out with
+func Validation(format string, args ...any) *Error {
+	return newError(ErrValidation, format, args...)
+}
+
+// Unauthorized reports missing or wrong credentials
+func Unauthorized(format string, args ...any) *Error {
+	return newError(ErrUnauthorized, format, args...)
+}
+
+// Locked reports a resource that is temporarily locked
+func Locked(format string, args ...any) *Error {
+	return newError(ErrLocked, format, args...)
+}
+
+// TooManyRequests reports a caller who has to slow down
+func TooManyRequests(format string, args ...any) *Error {
+	return newError(ErrTooManyRequests, format, args...)
+}
+
+// Wrap records cause as the reason for e
+func (e *Error) Wrap(cause error) *Error {
+	copied := *e
+	copied.cause = cause
+	return &copied
+}
+
+// WithDetails attaches data for the client, such as messages per field
+func (e *Error) WithDetails(details any) *Error {
+	copied := *e
+	copied.details = details
+	return &copied
+}
+
+// WithRetryAfter tells the client when trying again may succeed
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	copied := *e
+	copied.retryAfter = d
+	return &copied
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message() + ": " + e.cause.Error()
+	}
+	return e.Message()
+}
+
+// Unwrap exposes both the kind and the cause to errors.Is and errors.As
+func (e *Error) Unwrap() []error {
+	if e.cause != nil {
+		return []error{e.kind, e.cause}
+	}
+	return []error{e.kind}
+}
+
+// Kind returns the sentinel the error was created with
+func (e *Error) Kind() error { return e.kind }
+
+// Message returns the text meant for clients, without the cause
+func (e *Error) Message() string { return fmt.Sprintf(e.format, e.args...) }
+
+// MessageIn returns the text meant for clients translated into lang.
+// Arguments of type i18n.Text, such as entity names, are translated too.
+func (e *Error) MessageIn(lang string) string { return i18n.Sprintf(lang, e.format, e.args...) }
+
+// Details returns the data attached with WithDetails
+func (e *Error) Details() any { return e.details }
+
+// RetryAfter returns the delay set with WithRetryAfter, or zero
+func (e *Error) RetryAfter() time.Duration { return e.retryAfter }
+
+// IsNotFound reports whether err is a NotFound error
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage: oms [command]
+
+Commands:
+  serve      Start the HTTP server (default)
+  migrate    Manage database schema migrations
+  seed       Load reference, demo or load test data
+  config     Print the effective configuration
+  help       Show this message
+`
+
+// Run executes the subcommand named by args[0] and returns the process exit code
+func Run(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return 2
+	}
+
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:])
+	case "seed":
+		return runSeed(args[1:])
+	case "config":
+		return runConfig(args[1:])
+	case "help", "-h", "--help":
+		printUsage(os.Stdout)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		printUsage(os.Stderr)
+		return 2
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, usage)
+}
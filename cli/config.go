@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"oms/config"
+	"os"
+)
+
+const configUsage = `Usage: oms config print [--redacted]
+
+Prints the effective configuration after defaults, the config file, .env and
+environment variables are layered, then reports any invalid settings.
+
+Flags:
+  --redacted   mask secrets such as DB_PASSWORD and JWT_SECRET
+`
+
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, configUsage)
+	}
+	redact := flags.Bool("redacted", false, "mask secrets")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	for _, entry := range cfg.Entries(*redact) {
+		fmt.Println(entry)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
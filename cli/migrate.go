@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"oms/config"
+	"oms/connection"
+	migrations "oms/migration"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+)
+
+const migrateUsage = `Usage: oms migrate <subcommand>
+
+Subcommands:
+  up [N]           Apply all pending migrations, or the next N
+  down [N]         Roll back the last N applied migrations (default 1)
+  status           Show applied and pending migrations
+  create NAME      Create a new NNNN_NAME.up.sql/.down.sql pair
+                   in migration/sql (override with -dir)
+  redo             Roll back and re-apply the last migration
+  force VERSION    Mark VERSION and earlier as applied without running SQL
+`
+
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	subcommand, rest := args[0], args[1:]
+
+	// Creating files does not need a database connection
+	if subcommand == "create" {
+		flags := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+		dir := flags.String("dir", migrations.DefaultSQLDir, "directory to create the migration files in")
+		if err := flags.Parse(rest); err != nil {
+			return 2
+		}
+		if flags.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "migrate create requires exactly one NAME")
+			return 2
+		}
+
+		upPath, downPath, err := migrations.CreateMigration(*dir, flags.Arg(0))
+		if err != nil {
+			log.Printf("Failed to create migration: %v", err)
+			return 1
+		}
+
+		fmt.Printf("Created %s\nCreated %s\n", upPath, downPath)
+		return 0
+	}
+
+	switch subcommand {
+	case "up", "down", "redo", "force", "status":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate subcommand %q\n\n", subcommand)
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return 1
+	}
+
+	db, err := connection.OpenMasterDB(*cfg)
+	if err != nil {
+		log.Printf("Failed to connect to master database: %v", err)
+		return 1
+	}
+	defer connection.CloseDB(db)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	manager := migrations.NewMigrationManager(db)
+	manager.SetDriftPolicy(cfg.MigrationDriftPolicy)
+	manager.SetLockTimeout(cfg.MigrationLockTimeout)
+
+	switch subcommand {
+	case "up":
+		n, countErr := optionalCount(rest, 0)
+		if countErr != nil {
+			fmt.Fprintln(os.Stderr, countErr)
+			return 2
+		}
+		err = manager.Up(ctx, n)
+	case "down":
+		n, countErr := optionalCount(rest, 1)
+		if countErr != nil {
+			fmt.Fprintln(os.Stderr, countErr)
+			return 2
+		}
+		err = manager.Down(ctx, n)
+	case "redo":
+		err = manager.Redo(ctx)
+	case "force":
+		if len(rest) != 1 {
+			fmt.Fprintln(os.Stderr, "migrate force requires exactly one VERSION")
+			return 2
+		}
+		err = manager.Force(ctx, rest[0])
+	case "status":
+		err = printStatus(ctx, manager)
+	}
+
+	if err != nil {
+		log.Printf("Migration %s failed: %v", subcommand, err)
+		return 1
+	}
+
+	return 0
+}
+
+func printStatus(ctx context.Context, manager *migrations.MigrationManager) error {
+	statuses, err := manager.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATUS\tAPPLIED AT")
+
+	pending := 0
+	for _, status := range statuses {
+		if !status.Applied {
+			pending++
+			fmt.Fprintf(w, "%s\tpending\t-\n", status.Version)
+			continue
+		}
+		state := "applied"
+		if status.Modified {
+			state = "applied (modified)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status.Version, state, status.AppliedAt.Format("2006-01-02 15:04:05"))
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d applied, %d pending\n", len(statuses)-pending, pending)
+	return nil
+}
+
+func optionalCount(args []string, fallback int) (int, error) {
+	if len(args) == 0 {
+		return fallback, nil
+	}
+	if len(args) > 1 {
+		return 0, fmt.Errorf("expected at most one count argument")
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid count %q: must be a positive integer", args[0])
+	}
+
+	return n, nil
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"oms/config"
+	"oms/connection"
+	"oms/seed"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+)
+
+const seedUsage = `Usage: oms seed [flags] SET [SET...]
+       oms seed list
+
+Loads named seed sets. Sets upsert their rows, so running one again is safe,
+and sets they depend on are loaded first.
+
+Flags:
+  -orders N    number of orders the loadtest set generates (default %d)
+`
+
+func runSeed(args []string) int {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, seedUsage, seed.DefaultLoadtestOrders)
+	}
+	orders := flags.Int("orders", seed.DefaultLoadtestOrders, "number of orders the loadtest set generates")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	if flags.Arg(0) == "list" {
+		printSeedSets()
+		return 0
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return 1
+	}
+
+	db, err := connection.OpenMasterDB(*cfg)
+	if err != nil {
+		log.Printf("Failed to connect to master database: %v", err)
+		return 1
+	}
+	defer connection.CloseDB(db)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	seeder := seed.NewSeeder(db)
+	seeder.SetLoadtestOrders(*orders)
+
+	if err := seeder.Run(ctx, flags.Args()...); err != nil {
+		log.Printf("Seeding failed: %v", err)
+		return 1
+	}
+
+	return 0
+}
+
+func printSeedSets() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SET\tDEPENDS ON\tDESCRIPTION")
+	for _, set := range seed.Sets() {
+		dependsOn := "-"
+		if len(set.DependsOn) > 0 {
+			dependsOn = strings.Join(set.DependsOn, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", set.Name, dependsOn, set.Description)
+	}
+	w.Flush()
+}
@@ -1,48 +1,426 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"oms/money"
+	"oms/ratelimit"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
-var Conf Config
+// EnvDevelopment is the APP_ENV value for local development
+const EnvDevelopment = "development"
 
+const (
+	// DefaultConfigFile is the optional YAML file read when CONFIG_FILE is not set
+	DefaultConfigFile = "config.yaml"
+	// DefaultEnvFile is the optional dotenv file read on top of the YAML file
+	DefaultEnvFile = ".env"
+
+	secretFileSuffix = "_FILE"
+	redacted         = "********"
+
+	// developmentJWTSecret signs tokens when APP_ENV=development and no
+	// JWT_SECRET is given. It is public, so any other environment must set one.
+	developmentJWTSecret = "oms-development-only-jwt-signing-secret"
+	// developmentMFAEncryptionKey is the MFA_ENCRYPTION_KEY counterpart
+	developmentMFAEncryptionKey = "oms-development-only-mfa-encryption-key"
+)
+
+// Config holds every setting of the service. Each field is read from the key
+// in its mapstructure tag; `default` gives the value used when no source sets
+// it, and `secret` marks values that may come from a KEY_FILE and are hidden
+// when printed redacted.
 type Config struct {
 	DBUserRead                 string        `mapstructure:"DB_USER_READ"`
 	DBUserWrite                string        `mapstructure:"DB_USER_WRITE"`
-	DBPassword                 string        `mapstructure:"DB_PASSWORD"`
-	DBDriver                   string        `mapstructure:"DB_DRIVER"`
+	DBPassword                 string        `mapstructure:"DB_PASSWORD" secret:"true"`
+	DBDriver                   string        `mapstructure:"DB_DRIVER" default:"postgres"`
 	DBName                     string        `mapstructure:"DB_NAME"`
 	DBHostRead                 string        `mapstructure:"DB_HOST_READ"`
 	DBHostWrite                string        `mapstructure:"DB_HOST_WRITE"`
-	DBPortRead                 string        `mapstructure:"DB_PORT_READ"`
-	DBPortWrite                string        `mapstructure:"DB_PORT_WRITE"`
-	DBMaxOpenConnection        int           `mapstructure:"DB_MAX_OPEN_CONNECTION"`
-	DBMaxIdleConnection        int           `mapstructure:"DB_MAX_IDLE_CONNECTION"`
-	DBConnMaxLife              time.Duration `mapstructure:"DB_CONN_MAX_LIFE"`
-	Port                       string        `mapstructure:"PORT"`
-	RedisHost                  string        `mapstructure:"REDIS_HOST"`
-	RedisPort                  string        `mapstructure:"REDIS_PORT"`
-	AccessTokenExpirationTime  time.Duration `mapstructure:"ACCESS_TOKEN_EXPIRATION_TIME"`
-	RefreshTokenExpirationTime time.Duration `mapstructure:"REFRESH_TOKEN_EXPIRATION_TIME"`
+	DBPortRead                 string        `mapstructure:"DB_PORT_READ" default:"5432"`
+	DBPortWrite                string        `mapstructure:"DB_PORT_WRITE" default:"5432"`
+	DBMaxOpenConnection        int           `mapstructure:"DB_MAX_OPEN_CONNECTION" default:"10"`
+	DBMaxIdleConnection        int           `mapstructure:"DB_MAX_IDLE_CONNECTION" default:"5"`
+	DBConnMaxLife              time.Duration `mapstructure:"DB_CONN_MAX_LIFE" default:"5m"`
+	DBReadBalancer             string        `mapstructure:"DB_READ_BALANCER" default:"round_robin"`
+	MigrateOnBoot              bool          `mapstructure:"MIGRATE_ON_BOOT" default:"false"`
+	MigrationDriftPolicy       string        `mapstructure:"MIGRATION_DRIFT_POLICY" default:"fail"`
+	MigrationLockTimeout       time.Duration `mapstructure:"MIGRATION_LOCK_TIMEOUT" default:"5m"`
+	AppEnv                     string        `mapstructure:"APP_ENV" default:"production"`
+	SeedOnBoot                 string        `mapstructure:"SEED_ON_BOOT"`
+	Port                       string        `mapstructure:"PORT" default:"8089"`
+	TrustedProxies             string        `mapstructure:"TRUSTED_PROXIES"`
+	RedisHost                  string        `mapstructure:"REDIS_HOST" default:"localhost"`
+	RedisPort                  string        `mapstructure:"REDIS_PORT" default:"6379"`
+	RedisPassword              string        `mapstructure:"REDIS_PASSWORD" secret:"true"`
+	JWTSecret                  string        `mapstructure:"JWT_SECRET" secret:"true"`
+	MFAEncryptionKey           string        `mapstructure:"MFA_ENCRYPTION_KEY" secret:"true"`
+	MFAIssuer                  string        `mapstructure:"MFA_ISSUER" default:"OMS"`
+	MFAChallengeTTL            time.Duration `mapstructure:"MFA_CHALLENGE_TTL" default:"5m"`
+	MFARequiredForAdmin        bool          `mapstructure:"MFA_REQUIRED_FOR_ADMIN" default:"false"`
+	AccessTokenExpirationTime  time.Duration `mapstructure:"ACCESS_TOKEN_EXPIRATION_TIME" default:"20m"`
+	RefreshTokenExpirationTime time.Duration `mapstructure:"REFRESH_TOKEN_EXPIRATION_TIME" default:"200m"`
+	RequestTimeout             time.Duration `mapstructure:"REQUEST_TIMEOUT" default:"10s"`
+	ListRequestTimeout         time.Duration `mapstructure:"LIST_REQUEST_TIMEOUT" default:"30s"`
+	ShutdownTimeout            time.Duration `mapstructure:"SHUTDOWN_TIMEOUT" default:"15s"`
+	ShutdownDrainDelay         time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY" default:"0s"`
+	HealthCheckTimeout         time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+	ReplicaMaxLag              time.Duration `mapstructure:"REPLICA_MAX_LAG" default:"30s"`
+	ReplicaLagCheckInterval    time.Duration `mapstructure:"REPLICA_LAG_CHECK_INTERVAL" default:"5s"`
+	ReplicaRetryBackoff        time.Duration `mapstructure:"REPLICA_RETRY_BACKOFF" default:"5s"`
+	ReadYourWritesWindow       time.Duration `mapstructure:"READ_YOUR_WRITES_WINDOW" default:"5s"`
+	LoginMaxFailures           int           `mapstructure:"LOGIN_MAX_FAILURES" default:"5"`
+	LoginMaxIPFailures         int           `mapstructure:"LOGIN_MAX_IP_FAILURES" default:"20"`
+	LoginFailureWindow         time.Duration `mapstructure:"LOGIN_FAILURE_WINDOW" default:"15m"`
+	LoginLockoutDuration       time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION" default:"5m"`
+	LoginMaxLockoutDuration    time.Duration `mapstructure:"LOGIN_MAX_LOCKOUT_DURATION" default:"24h"`
+	PasswordResetTokenTTL      time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_TTL" default:"30m"`
+	PasswordResetURL           string        `mapstructure:"PASSWORD_RESET_URL" default:"http://localhost:8089/reset-password"`
+	EmailVerificationTokenTTL  time.Duration `mapstructure:"EMAIL_VERIFICATION_TOKEN_TTL" default:"24h"`
+	EmailVerificationURL       string        `mapstructure:"EMAIL_VERIFICATION_URL" default:"http://localhost:8089/verify-email"`
+	Notifier                   string        `mapstructure:"NOTIFIER" default:"log"`
+	NotifierFileDir            string        `mapstructure:"NOTIFIER_FILE_DIR" default:"notifications"`
+	OrderCancellationFee       string        `mapstructure:"ORDER_CANCELLATION_FEE" default:"0"`
+	TrashRetention             time.Duration `mapstructure:"TRASH_RETENTION" default:"720h"`
+	TrashPurgeInterval         time.Duration `mapstructure:"TRASH_PURGE_INTERVAL" default:"24h"`
+	RateLimitEnabled           bool          `mapstructure:"RATE_LIMIT_ENABLED" default:"true"`
+	RateLimitDefault           string        `mapstructure:"RATE_LIMIT_DEFAULT" default:"120/1m"`
+	RateLimitLogin             string        `mapstructure:"RATE_LIMIT_LOGIN" default:"10/1m"`
+	RateLimitOrderCreate       string        `mapstructure:"RATE_LIMIT_ORDER_CREATE" default:"60/1m"`
+	OtelServiceName            string        `mapstructure:"OTEL_SERVICE_NAME" default:"oms"`
+	OtelExporter               string        `mapstructure:"OTEL_EXPORTER" default:"none"`
+	OtelExporterEndpoint       string        `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OtelExporterInsecure       bool          `mapstructure:"OTEL_EXPORTER_OTLP_INSECURE" default:"false"`
+}
+
+// LoadConfig loads the configuration and validates it
+func LoadConfig() (*Config, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Load reads the configuration without validating it. Sources are layered,
+// each overriding the one before:
+//
+//  1. defaults from the Config struct tags
+//  2. the YAML file named by CONFIG_FILE, or config.yaml if it exists
+//  3. the .env file, if it exists
+//  4. environment variables
+//
+// A secret KEY may instead be given as KEY_FILE, the path of a file holding
+// the value, as with Docker and Kubernetes secrets. KEY_FILE belongs to the
+// layer that sets it, so it overrides a KEY from a lower layer and is
+// overridden by a KEY from a higher one.
+func Load() (*Config, error) {
+	v := viper.New()
+
+	for _, field := range fields() {
+		if field.def != "" {
+			v.SetDefault(field.key, field.def)
+		}
+		if err := v.BindEnv(field.key); err != nil {
+			return nil, err
+		}
+		if field.secret {
+			if err := v.BindEnv(field.key + secretFileSuffix); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	configFile, required := os.Getenv("CONFIG_FILE"), true
+	if configFile == "" {
+		configFile, required = DefaultConfigFile, false
+	}
+	yamlSettings, err := mergeFile(v, configFile, "yaml", required)
+	if err != nil {
+		return nil, err
+	}
+	envFileSettings, err := mergeFile(v, DefaultEnvFile, "env", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolveSecretFiles(v, []map[string]any{yamlSettings, envFileSettings}); err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("error decoding configuration: %w", err)
+	}
+
+	if cfg.AppEnv == EnvDevelopment {
+		if cfg.JWTSecret == "" {
+			cfg.JWTSecret = developmentJWTSecret
+		}
+		if cfg.MFAEncryptionKey == "" {
+			cfg.MFAEncryptionKey = developmentMFAEncryptionKey
+		}
+	}
+
+	return &cfg, nil
+}
+
+// mergeFile layers a config file over what v already holds and returns the
+// file's own settings. A missing file is only an error when it was asked for
+// explicitly.
+func mergeFile(v *viper.Viper, path, format string, required bool) (map[string]any, error) {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !required {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	file := viper.New()
+	file.SetConfigFile(path)
+	file.SetConfigType(format)
+	if err := file.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+
+	settings := file.AllSettings()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("error merging config file %s: %w", path, err)
+	}
+
+	return settings, nil
+}
+
+// resolveSecretFiles reads each KEY_FILE that is set in a higher layer than
+// KEY. files holds the settings of each config file, lowest layer first.
+func resolveSecretFiles(v *viper.Viper, files []map[string]any) error {
+	for _, field := range fields() {
+		if !field.secret {
+			continue
+		}
+
+		fileLayer := sourceLayer(field.key+secretFileSuffix, files)
+		if fileLayer == 0 {
+			continue
+		}
+		valueLayer := sourceLayer(field.key, files)
+		if valueLayer == fileLayer {
+			return fmt.Errorf("%s and %s%s are both set in the same source; use one", field.key, field.key, secretFileSuffix)
+		}
+		if valueLayer > fileLayer {
+			continue
+		}
+
+		path := v.GetString(field.key + secretFileSuffix)
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s%s: %w", field.key, secretFileSuffix, err)
+		}
+		v.Set(field.key, strings.TrimRight(string(content), "\r\n"))
+	}
+
+	return nil
+}
+
+// sourceLayer tells which source sets key: 0 for none, then 1 up for each of
+// files in order, and above those the environment
+func sourceLayer(key string, files []map[string]any) int {
+	if os.Getenv(key) != "" {
+		return len(files) + 1
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		if value, ok := files[i][strings.ToLower(key)]; ok && fmt.Sprint(value) != "" {
+			return i + 1
+		}
+	}
+	return 0
 }
 
-func LoadConfig() *Config {
-	viper.SetConfigFile(".env")
-	viper.SetConfigType("env")
-	viper.AddConfigPath(".")
+// Validate reports every invalid setting at once
+func (c Config) Validate() error {
+	var problems []string
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.DBUserRead != "", "DB_USER_READ", "must be set")
+	check(c.DBUserWrite != "", "DB_USER_WRITE", "must be set")
+	check(c.DBName != "", "DB_NAME", "must be set")
+	check(c.DBHostRead != "", "DB_HOST_READ", "must be set")
+	check(c.DBHostWrite != "", "DB_HOST_WRITE", "must be set")
+	check(c.DBDriver == "postgres", "DB_DRIVER", "only postgres is supported, got %q", c.DBDriver)
+	for _, proxy := range c.TrustedProxyList() {
+		check(isIPOrCIDR(proxy), "TRUSTED_PROXIES", "must list IP addresses or CIDR ranges, got %q", proxy)
+	}
+	check(isPort(c.DBPortRead), "DB_PORT_READ", "must be a port number, got %q", c.DBPortRead)
+	check(isPort(c.DBPortWrite), "DB_PORT_WRITE", "must be a port number, got %q", c.DBPortWrite)
+	check(c.DBMaxOpenConnection > 0, "DB_MAX_OPEN_CONNECTION", "must be greater than 0")
+	check(c.DBMaxIdleConnection >= 0, "DB_MAX_IDLE_CONNECTION", "must not be negative")
+	check(c.DBMaxIdleConnection <= c.DBMaxOpenConnection, "DB_MAX_IDLE_CONNECTION",
+		"must not exceed DB_MAX_OPEN_CONNECTION (%d)", c.DBMaxOpenConnection)
+	check(oneOf(c.DBReadBalancer, "round_robin", "least_connections"), "DB_READ_BALANCER",
+		"must be round_robin or least_connections, got %q", c.DBReadBalancer)
+	check(oneOf(c.MigrationDriftPolicy, "fail", "warn"), "MIGRATION_DRIFT_POLICY",
+		"must be fail or warn, got %q", c.MigrationDriftPolicy)
+	check(c.AppEnv != "", "APP_ENV", "must be set")
+	check(isPort(c.Port), "PORT", "must be a port number, got %q", c.Port)
+	check(c.RedisHost != "", "REDIS_HOST", "must be set")
+	check(isPort(c.RedisPort), "REDIS_PORT", "must be a port number, got %q", c.RedisPort)
+	check(c.JWTSecret != "", "JWT_SECRET", "must be set outside development")
+	check(c.JWTSecret == "" || len(c.JWTSecret) >= 32, "JWT_SECRET", "must be at least 32 bytes")
+	check(c.MFAEncryptionKey != "", "MFA_ENCRYPTION_KEY", "must be set outside development")
+	check(c.MFAEncryptionKey == "" || len(c.MFAEncryptionKey) >= 32, "MFA_ENCRYPTION_KEY", "must be at least 32 bytes")
+	check(c.MFAIssuer != "", "MFA_ISSUER", "must be set")
+	check(c.LoginMaxFailures > 0, "LOGIN_MAX_FAILURES", "must be greater than 0")
+	check(c.LoginMaxIPFailures > 0, "LOGIN_MAX_IP_FAILURES", "must be greater than 0")
+	check(c.LoginMaxLockoutDuration >= c.LoginLockoutDuration, "LOGIN_MAX_LOCKOUT_DURATION",
+		"must not be shorter than LOGIN_LOCKOUT_DURATION (%s)", c.LoginLockoutDuration)
+	check(c.PasswordResetURL != "", "PASSWORD_RESET_URL", "must be set")
+	check(c.EmailVerificationURL != "", "EMAIL_VERIFICATION_URL", "must be set")
+	check(oneOf(c.Notifier, "log", "file"), "NOTIFIER", "must be log or file, got %q", c.Notifier)
+	check(c.Notifier != "file" || c.NotifierFileDir != "", "NOTIFIER_FILE_DIR", "must be set when NOTIFIER=file")
+	check(oneOf(c.OtelExporter, "", "none", "stdout", "otlp"), "OTEL_EXPORTER",
+		"must be none, stdout or otlp, got %q", c.OtelExporter)
 
-	viper.AutomaticEnv()
+	cancellationFee, err := money.Parse(c.OrderCancellationFee)
+	check(err == nil && cancellationFee >= 0, "ORDER_CANCELLATION_FEE",
+		"must be a non-negative amount in taka such as 25.50, got %q", c.OrderCancellationFee)
 
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatal("Error reading env file", err)
+	policies := map[string]string{
+		"RATE_LIMIT_DEFAULT":      c.RateLimitDefault,
+		"RATE_LIMIT_LOGIN":        c.RateLimitLogin,
+		"RATE_LIMIT_ORDER_CREATE": c.RateLimitOrderCreate,
 	}
+	for key, value := range policies {
+		_, err := ratelimit.ParsePolicy(key, value)
+		check(err == nil, key, "%v", err)
+	}
+
+	positive := map[string]time.Duration{
+		"DB_CONN_MAX_LIFE":              c.DBConnMaxLife,
+		"MIGRATION_LOCK_TIMEOUT":        c.MigrationLockTimeout,
+		"ACCESS_TOKEN_EXPIRATION_TIME":  c.AccessTokenExpirationTime,
+		"REFRESH_TOKEN_EXPIRATION_TIME": c.RefreshTokenExpirationTime,
+		"REQUEST_TIMEOUT":               c.RequestTimeout,
+		"LIST_REQUEST_TIMEOUT":          c.ListRequestTimeout,
+		"SHUTDOWN_TIMEOUT":              c.ShutdownTimeout,
+		"HEALTH_CHECK_TIMEOUT":          c.HealthCheckTimeout,
+		"REPLICA_MAX_LAG":               c.ReplicaMaxLag,
+		"REPLICA_LAG_CHECK_INTERVAL":    c.ReplicaLagCheckInterval,
+		"REPLICA_RETRY_BACKOFF":         c.ReplicaRetryBackoff,
+		"READ_YOUR_WRITES_WINDOW":       c.ReadYourWritesWindow,
+		"LOGIN_FAILURE_WINDOW":          c.LoginFailureWindow,
+		"LOGIN_LOCKOUT_DURATION":        c.LoginLockoutDuration,
+		"LOGIN_MAX_LOCKOUT_DURATION":    c.LoginMaxLockoutDuration,
+		"PASSWORD_RESET_TOKEN_TTL":      c.PasswordResetTokenTTL,
+		"EMAIL_VERIFICATION_TOKEN_TTL":  c.EmailVerificationTokenTTL,
+		"MFA_CHALLENGE_TTL":             c.MFAChallengeTTL,
+		"TRASH_RETENTION":               c.TrashRetention,
+	}
+	for key, value := range positive {
+		check(value > 0, key, "must be a positive duration such as 30s, got %s", value)
+	}
+	check(c.ShutdownDrainDelay >= 0, "SHUTDOWN_DRAIN_DELAY", "must not be negative")
+	check(c.TrashPurgeInterval >= 0, "TRASH_PURGE_INTERVAL", "must not be negative, use 0 to disable purging")
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	return fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// Entries returns every setting as KEY=value lines sorted by key. Secrets
+// are masked when redact is set.
+func (c Config) Entries(redact bool) []string {
+	value := reflect.ValueOf(c)
 
-	if err := viper.Unmarshal(&Conf); err != nil {
-		log.Fatal("Error reading env file", err)
+	var entries []string
+	for i, field := range fields() {
+		formatted := fmt.Sprint(value.Field(i).Interface())
+		if redact && field.secret && formatted != "" {
+			formatted = redacted
+		}
+		entries = append(entries, field.key+"="+formatted)
 	}
 
-	return &Conf
+	sort.Strings(entries)
+	return entries
+}
+
+type fieldInfo struct {
+	key    string
+	def    string
+	secret bool
+}
+
+// fields describes the Config struct fields in declaration order
+func fields() []fieldInfo {
+	t := reflect.TypeOf(Config{})
+
+	infos := make([]fieldInfo, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag
+		infos = append(infos, fieldInfo{
+			key:    tag.Get("mapstructure"),
+			def:    tag.Get("default"),
+			secret: tag.Get("secret") == "true",
+		})
+	}
+
+	return infos
+}
+
+// TrustedProxyList returns the addresses whose X-Forwarded-For header is
+// believed. With none, the client IP is always the connection's address.
+func (c Config) TrustedProxyList() []string {
+	var proxies []string
+	for _, entry := range strings.Split(c.TrustedProxies, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			proxies = append(proxies, entry)
+		}
+	}
+	return proxies
+}
+
+func isIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
+func isPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port > 0 && port < 65536
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
 }
@@ -4,93 +4,213 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"oms/config"
-	migrations "oms/migration"
-	"oms/model"
+	"os"
+	"strings"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	sqlLogger "gorm.io/gorm/logger"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
-func InitDB(cfg config.Config) (*gorm.DB, *gorm.DB) {
-	readDataSourceName := GetReadDSN(cfg)
-	writeDataSourceName := GetWriteDSN(cfg)
+// Replica is a read replica connection pool and the address it points at
+type Replica struct {
+	Address string
+	DB      *gorm.DB
+}
+
+// ReplicaDSN is the DSN for one entry of DB_HOST_READ
+type ReplicaDSN struct {
+	Address string
+	DSN     string
+}
 
+// InitDB connects to the master and to every read replica. The master must be
+// reachable at startup; an unreachable replica is only logged, since the
+// router keeps it out of rotation until it answers.
+func InitDB(cfg config.Config) (*gorm.DB, []Replica) {
 	// Connect to the master database
-	masterDB, err := gorm.Open(postgres.New(postgres.Config{
-		DSN:                  writeDataSourceName,
-		PreferSimpleProtocol: true,
-	}), &gorm.Config{
-		Logger: sqlLogger.Default,
-	})
+	masterDB, err := openDB(cfg, GetWriteDSN(cfg))
 	if err != nil {
 		log.Fatalf("error initializing master DB instance: %v", err)
 	}
 
-	// Connect to the read replica database
-	replicaDB, err := gorm.Open(postgres.New(postgres.Config{
-		DSN:                  readDataSourceName,
+	// Connect to the read replica databases
+	var replicas []Replica
+	for _, target := range GetReadDSNs(cfg) {
+		replicaDB, err := connect(cfg, target.DSN)
+		if err != nil {
+			log.Fatalf("error initializing replica DB instance %s: %v", target.Address, err)
+		}
+
+		if err := pingDB(replicaDB); err != nil {
+			log.Printf("Replica %s is not reachable yet: %v", target.Address, err)
+		}
+
+		replicas = append(replicas, Replica{Address: target.Address, DB: replicaDB})
+	}
+	if len(replicas) == 0 {
+		log.Fatalf("error initializing replica DB instance: DB_HOST_READ is empty")
+	}
+
+	log.Printf("Database initialization successful")
+	logPoolStats("Master", masterDB)
+	for _, replica := range replicas {
+		logPoolStats("Replica "+replica.Address, replica.DB)
+	}
+
+	return masterDB, replicas
+}
+
+// OpenMasterDB connects to the master database only, for tooling that never
+// reads from the replica such as the migration command.
+func OpenMasterDB(cfg config.Config) (*gorm.DB, error) {
+	return openDB(cfg, GetWriteDSN(cfg))
+}
+
+func openDB(cfg config.Config, dsn string) (*gorm.DB, error) {
+	db, err := connect(cfg, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pingDB(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// connect sets up a GORM handle and its pool without contacting the server
+func connect(cfg config.Config, dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN:                  dsn,
 		PreferSimpleProtocol: true,
 	}), &gorm.Config{
-		Logger: sqlLogger.Default,
+		Logger:               sqlLogger.Default,
+		DisableAutomaticPing: true,
 	})
 	if err != nil {
-		log.Fatalf("error initializing replica DB instance: %v", err)
+		return nil, err
 	}
 
-	// Get underlying sql.DB instances
-	sqlMasterDB, err := masterDB.DB()
-	if err != nil {
-		log.Fatalf("error getting master sql.DB: %v", err)
+	// Trace every query issued through this connection
+	if err := db.Use(tracing.NewPlugin(tracing.WithDBName(cfg.DBName), tracing.WithoutMetrics())); err != nil {
+		return nil, fmt.Errorf("error registering tracing: %w", err)
 	}
 
-	sqlReplicaDB, err := replicaDB.DB()
+	// Get underlying sql.DB instance
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("error getting replica sql.DB: %v", err)
+		return nil, fmt.Errorf("error getting sql.DB: %w", err)
 	}
 
 	// Set connection pool settings
-	sqlMasterDB.SetMaxOpenConns(cfg.DBMaxOpenConnection)
-	sqlMasterDB.SetMaxIdleConns(cfg.DBMaxIdleConnection)
-	sqlMasterDB.SetConnMaxLifetime(cfg.DBConnMaxLife * time.Second)
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConnection)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConnection)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLife)
 
-	sqlReplicaDB.SetMaxOpenConns(cfg.DBMaxOpenConnection)
-	sqlReplicaDB.SetMaxIdleConns(cfg.DBMaxIdleConnection)
-	sqlReplicaDB.SetConnMaxLifetime(cfg.DBConnMaxLife * time.Second)
+	return db, nil
+}
 
-	// Test connections
-	if err := sqlMasterDB.Ping(); err != nil {
-		log.Fatalf("error pinging master database: %v", err)
+func pingDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("error getting sql.DB: %w", err)
 	}
 
-	if err := sqlReplicaDB.Ping(); err != nil {
-		log.Fatalf("error pinging replica database: %v", err)
+	if err := sqlDB.Ping(); err != nil {
+		return fmt.Errorf("error pinging database: %w", err)
 	}
 
-	log.Printf("Database initialization successful")
-	log.Printf("Master DB - Open connections: %d, Idle connections: %d",
-		sqlMasterDB.Stats().OpenConnections, sqlMasterDB.Stats().Idle)
-	log.Printf("Replica DB - Open connections: %d, Idle connections: %d",
-		sqlReplicaDB.Stats().OpenConnections, sqlReplicaDB.Stats().Idle)
-
-	masterDB.AutoMigrate(&model.MigrationRecord{})
+	return nil
+}
 
-	err = migrations.Migrate(context.Background(), masterDB)
+func logPoolStats(name string, db *gorm.DB) {
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("error running migrations: %v", err)
+		return
 	}
 
-	return masterDB, replicaDB
+	log.Printf("%s DB - Open connections: %d, Idle connections: %d",
+		name, sqlDB.Stats().OpenConnections, sqlDB.Stats().Idle)
 }
 
-func GetReadDSN(c config.Config) string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		c.DBHostRead, c.DBPortRead, c.DBUserRead, c.DBPassword, c.DBName)
+// GetReadDSNs returns a DSN per replica. DB_HOST_READ is a comma separated
+// list of host or host:port entries; DB_PORT_READ is the default port.
+func GetReadDSNs(c config.Config) []ReplicaDSN {
+	var dsns []ReplicaDSN
+	for _, entry := range strings.Split(c.DBHostRead, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		host, port := entry, c.DBPortRead
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			host, port = h, p
+		}
+
+		dsns = append(dsns, ReplicaDSN{
+			Address: net.JoinHostPort(host, port),
+			DSN: fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable application_name=%s",
+				host, port, c.DBUserRead, c.DBPassword, c.DBName, applicationName()),
+		})
+	}
+
+	return dsns
 }
 
 func GetWriteDSN(c config.Config) string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		c.DBHostWrite, c.DBPortWrite, c.DBUserWrite, c.DBPassword, c.DBName)
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable application_name=%s",
+		c.DBHostWrite, c.DBPortWrite, c.DBUserWrite, c.DBPassword, c.DBName, applicationName())
+}
+
+// applicationName tags connections with the host they come from so sessions
+// can be told apart in pg_stat_activity, e.g. when waiting on a lock
+func applicationName() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "oms"
+	}
+
+	return "oms@" + hostname
+}
+
+// CloseDB closes the connection pools behind the given GORM handles.
+func CloseDB(dbs ...*gorm.DB) {
+	for _, db := range dbs {
+		if db == nil {
+			continue
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Printf("error getting sql.DB to close: %v", err)
+			continue
+		}
+
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("error closing database connection: %v", err)
+		}
+	}
+}
+
+// ReplicaLag reports how far the given replica trails its primary. A server
+// that is not in recovery is a primary and always reports zero lag.
+func ReplicaLag(ctx context.Context, db *gorm.DB) (time.Duration, error) {
+	var lagSeconds float64
+	err := db.WithContext(withoutRouting(ctx)).Raw(`SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`).Scan(&lagSeconds).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(lagSeconds * float64(time.Second)), nil
 }
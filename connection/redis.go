@@ -12,7 +12,7 @@ var redisClient *redis.Client
 func RedisConnect(cfg config.Config) {
 	redisClient = redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
-		Password: "",
+		Password: cfg.RedisPassword,
 		DB:       0,
 	})
 }
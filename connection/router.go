@@ -0,0 +1,345 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"oms/config"
+	"oms/utility"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLagCheckInterval     = 5 * time.Second
+	defaultReplicaMaxLag        = 30 * time.Second
+	defaultReadYourWritesWindow = 5 * time.Second
+	defaultReplicaRetryBackoff  = 5 * time.Second
+	maxReplicaRetryBackoff      = 2 * time.Minute
+)
+
+const (
+	// BalancerRoundRobin spreads reads evenly over healthy replicas
+	BalancerRoundRobin = "round_robin"
+	// BalancerLeastConnections sends each read to the healthy replica with
+	// the fewest connections in use
+	BalancerLeastConnections = "least_connections"
+)
+
+type routingContextKey struct{}
+
+// PinStore remembers which users wrote recently so their reads can be served
+// by the master until the replica has caught up
+type PinStore interface {
+	Pin(ctx context.Context, userID int64, ttl time.Duration) error
+	Pinned(ctx context.Context, userID int64) (bool, error)
+}
+
+// Router spreads reads issued through the replica handle over the healthy
+// replicas, and sends them to the master when no replica is healthy or the
+// user behind the request wrote within the read-your-writes window. A replica
+// is ejected when it is unreachable or lags too far behind, and an
+// unreachable one is retried with exponential backoff. Repositories keep
+// using their master and replica handles; the decision is made per
+// statement by GORM callbacks.
+type Router struct {
+	master    *gorm.DB
+	handle    *gorm.DB
+	replicas  []*replicaNode
+	pins      PinStore
+	balancer  string
+	next      atomic.Uint64
+	noReplica atomic.Bool
+	maxLag    time.Duration
+	interval  time.Duration
+	pinWindow time.Duration
+	backoff   time.Duration
+}
+
+// replicaNode tracks one replica. Only the check loop writes the retry
+// fields; the routing callbacks read healthy.
+type replicaNode struct {
+	address  string
+	db       *gorm.DB
+	sqlDB    *sql.DB
+	healthy  atomic.Bool
+	failures int
+	retryAt  time.Time
+}
+
+// NewRouter creates a router over the master and replicas. Reads are issued
+// through the first replica's handle. Replicas join the rotation after their
+// first successful check, so call Register to install the router and Run to
+// keep replica state up to date.
+func NewRouter(master *gorm.DB, replicas []Replica, pins PinStore, cfg config.Config) (*Router, error) {
+	if len(replicas) == 0 {
+		return nil, fmt.Errorf("at least one replica is required")
+	}
+
+	router := &Router{
+		master:    master,
+		handle:    replicas[0].DB,
+		pins:      pins,
+		balancer:  cfg.DBReadBalancer,
+		maxLag:    cfg.ReplicaMaxLag,
+		interval:  cfg.ReplicaLagCheckInterval,
+		pinWindow: cfg.ReadYourWritesWindow,
+		backoff:   cfg.ReplicaRetryBackoff,
+	}
+
+	switch router.balancer {
+	case "":
+		router.balancer = BalancerRoundRobin
+	case BalancerRoundRobin, BalancerLeastConnections:
+	default:
+		return nil, fmt.Errorf("unknown DB_READ_BALANCER %q", router.balancer)
+	}
+
+	for _, replica := range replicas {
+		sqlDB, err := replica.DB.DB()
+		if err != nil {
+			return nil, fmt.Errorf("error getting sql.DB for replica %s: %w", replica.Address, err)
+		}
+		router.replicas = append(router.replicas, &replicaNode{address: replica.Address, db: replica.DB, sqlDB: sqlDB})
+	}
+	router.noReplica.Store(true)
+
+	if router.maxLag <= 0 {
+		router.maxLag = defaultReplicaMaxLag
+	}
+	if router.interval <= 0 {
+		router.interval = defaultLagCheckInterval
+	}
+	if router.pinWindow <= 0 {
+		router.pinWindow = defaultReadYourWritesWindow
+	}
+	if router.backoff <= 0 {
+		router.backoff = defaultReplicaRetryBackoff
+	}
+
+	return router, nil
+}
+
+// Register installs the routing callbacks on the replica handle and the
+// pinning callbacks on the master handle
+func (r *Router) Register() error {
+	reads := r.handle.Callback()
+	if err := reads.Query().Before("gorm:query").Register("oms:route_read", r.routeRead); err != nil {
+		return fmt.Errorf("error registering read routing: %w", err)
+	}
+	if err := reads.Row().Before("gorm:row").Register("oms:route_read", r.routeRead); err != nil {
+		return fmt.Errorf("error registering read routing: %w", err)
+	}
+
+	writes := r.master.Callback()
+	if err := writes.Create().After("gorm:create").Register("oms:pin_writer", r.pinWriter); err != nil {
+		return fmt.Errorf("error registering write pinning: %w", err)
+	}
+	if err := writes.Update().After("gorm:update").Register("oms:pin_writer", r.pinWriter); err != nil {
+		return fmt.Errorf("error registering write pinning: %w", err)
+	}
+	if err := writes.Delete().After("gorm:delete").Register("oms:pin_writer", r.pinWriter); err != nil {
+		return fmt.Errorf("error registering write pinning: %w", err)
+	}
+	if err := writes.Raw().After("gorm:raw").Register("oms:pin_writer", r.pinWriter); err != nil {
+		return fmt.Errorf("error registering write pinning: %w", err)
+	}
+
+	return nil
+}
+
+// Run checks the replicas every interval until ctx is cancelled
+func (r *Router) Run(ctx context.Context) {
+	r.checkReplicas(ctx)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkReplicas(ctx)
+		}
+	}
+}
+
+func (r *Router) checkReplicas(ctx context.Context) {
+	healthy := 0
+	for _, node := range r.replicas {
+		r.checkReplica(ctx, node)
+		if node.healthy.Load() {
+			healthy++
+		}
+	}
+
+	noReplica := healthy == 0
+	if r.noReplica.Swap(noReplica) != noReplica {
+		if noReplica {
+			log.Printf("No healthy replicas, routing reads to master")
+		} else {
+			log.Printf("Routing reads to %d of %d replicas", healthy, len(r.replicas))
+		}
+	}
+}
+
+func (r *Router) checkReplica(ctx context.Context, node *replicaNode) {
+	// An unreachable replica waits out its backoff before the next attempt
+	if !node.healthy.Load() && time.Now().Before(node.retryAt) {
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, r.interval)
+	defer cancel()
+
+	lag, err := ReplicaLag(checkCtx, node.db)
+	switch {
+	case err != nil:
+		node.failures++
+		backoff := r.backoff << min(node.failures-1, 16)
+		if backoff > maxReplicaRetryBackoff || backoff <= 0 {
+			backoff = maxReplicaRetryBackoff
+		}
+		node.retryAt = time.Now().Add(backoff)
+		if node.healthy.Swap(false) || node.failures == 1 {
+			log.Printf("Ejected replica %s: %v (retrying in %s)", node.address, err, backoff)
+		}
+
+	case lag > r.maxLag:
+		// Lag is rechecked every interval; the replica is up, just behind
+		node.failures = 0
+		node.retryAt = time.Time{}
+		if node.healthy.Swap(false) {
+			log.Printf("Ejected replica %s: lag %s exceeds %s", node.address, lag.Round(time.Millisecond), r.maxLag)
+		}
+
+	default:
+		node.failures = 0
+		node.retryAt = time.Time{}
+		if !node.healthy.Swap(true) {
+			log.Printf("Replica %s in rotation (lag %s)", node.address, lag.Round(time.Millisecond))
+		}
+	}
+}
+
+// routeRead picks the connection pool for a read: a healthy replica, or the
+// master. Reads inside a transaction already have their own connection and
+// are left alone, as are checks that must reach a specific replica.
+func (r *Router) routeRead(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if db.Statement.ConnPool != r.handle.ConnPool || ctx == nil || ctx.Value(routingContextKey{}) != nil {
+		return
+	}
+
+	if r.pinnedToMaster(ctx) {
+		db.Statement.ConnPool = r.master.ConnPool
+		return
+	}
+
+	node := r.pickReplica()
+	if node == nil {
+		db.Statement.ConnPool = r.master.ConnPool
+		return
+	}
+
+	db.Statement.ConnPool = node.db.ConnPool
+}
+
+// pickReplica returns a healthy replica chosen by the configured balancer,
+// or nil when every replica is out of rotation
+func (r *Router) pickReplica() *replicaNode {
+	if r.balancer == BalancerLeastConnections {
+		var best *replicaNode
+		bestInUse := 0
+		for _, node := range r.replicas {
+			if !node.healthy.Load() {
+				continue
+			}
+			if inUse := node.sqlDB.Stats().InUse; best == nil || inUse < bestInUse {
+				best, bestInUse = node, inUse
+			}
+		}
+		return best
+	}
+
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		node := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if node.healthy.Load() {
+			return node
+		}
+	}
+
+	return nil
+}
+
+// pinnedToMaster reports whether the user behind ctx wrote recently
+func (r *Router) pinnedToMaster(ctx context.Context) bool {
+	userID, ok := utility.UserIDFromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	pinned, err := r.pins.Pinned(ctx, userID)
+	if err != nil {
+		// Without the pin store a stale read is possible; the master is safe
+		return true
+	}
+
+	return pinned
+}
+
+// pinWriter pins the acting user to the master after a successful write
+func (r *Router) pinWriter(db *gorm.DB) {
+	if db.Error != nil || db.RowsAffected == 0 || db.Statement.Context == nil {
+		return
+	}
+
+	userID, ok := utility.UserIDFromContext(db.Statement.Context)
+	if !ok {
+		return
+	}
+
+	if err := r.pins.Pin(db.Statement.Context, userID, r.pinWindow); err != nil {
+		log.Printf("Failed to pin reads to master for user %d: %v", userID, err)
+	}
+}
+
+// withoutRouting marks ctx so statements always run on the handle they were
+// issued on, which is needed to look at the replica itself
+func withoutRouting(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routingContextKey{}, true)
+}
+
+type redisPinStore struct {
+	client *redis.Client
+}
+
+// NewRedisPinStore keeps read-your-writes pins in Redis so they hold across
+// every app instance behind the load balancer
+func NewRedisPinStore(client *redis.Client) PinStore {
+	return &redisPinStore{client: client}
+}
+
+func (s *redisPinStore) Pin(ctx context.Context, userID int64, ttl time.Duration) error {
+	return s.client.WithContext(ctx).Set(pinKey(userID), 1, ttl).Err()
+}
+
+func (s *redisPinStore) Pinned(ctx context.Context, userID int64) (bool, error) {
+	n, err := s.client.WithContext(ctx).Exists(pinKey(userID)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func pinKey(userID int64) string {
+	return "oms:db:pin:" + strconv.FormatInt(userID, 10)
+}
@@ -3,9 +3,32 @@ package consts
 const (
 	UserIdKey      = "UserID"
 	AccessTokenKey = "AccessToken"
+	MFAVerifiedKey = "MFAVerified"
 
-	OrderStatusCancelled = "cancelled"
-	OrderStatusPending   = "pending"
+	OrderStatusPending        = "pending"
+	OrderStatusConfirmed      = "confirmed"
+	OrderStatusPickedUp       = "picked_up"
+	OrderStatusInTransit      = "in_transit"
+	OrderStatusOutForDelivery = "out_for_delivery"
+	OrderStatusDelivered      = "delivered"
+	OrderStatusFailedDelivery = "failed_delivery"
+	OrderStatusReturned       = "returned"
+	OrderStatusCancelled      = "cancelled"
 
 	OrderTypeDelivery = "delivery"
+
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureAccountLocked      = "account_locked"
+	LoginFailureIPBlocked          = "ip_blocked"
+	LoginFailureEmailNotVerified   = "email_not_verified"
+	LoginFailureInvalidMFACode     = "invalid_mfa_code"
+
+	RoleMerchant = "merchant"
+	RoleAdmin    = "admin"
+
+	HealthStatusOK           = "ok"
+	HealthStatusUp           = "up"
+	HealthStatusDown         = "down"
+	HealthStatusDegraded     = "degraded"
+	HealthStatusShuttingDown = "shutting_down"
 )
@@ -4,17 +4,28 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net"
 	"net/http"
 	"oms/config"
+	"oms/connection"
+	"oms/middleware"
+	migrations "oms/migration"
+	"oms/repository"
 	"oms/routes"
+	"oms/seed"
+	"oms/service"
+	"oms/telemetry"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+const defaultShutdownTimeout = 5 * time.Second
+
 func Serve(e *gin.Engine) {
 	logger := log.New(os.Stdout, "[OMS] ", log.LstdFlags|log.Lshortfile)
 
@@ -22,19 +33,97 @@ func Serve(e *gin.Engine) {
 
 	// Load configuration
 	logger.Println("Loading configuration...")
-	cfg := config.LoadConfig()
-	if cfg == nil {
-		logger.Fatal("Failed to load configuration")
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Initialize tracing
+	logger.Println("Initializing tracing...")
+	shutdownTracer, err := telemetry.InitTracer(context.Background(), *cfg)
+	if err != nil {
+		logger.Fatalf("Failed to initialize tracing: %v", err)
+	}
+
+	// Only proxies we run may set the client IP that rate limits and login
+	// lockouts count against; anyone else could forge X-Forwarded-For
+	if err := e.SetTrustedProxies(cfg.TrustedProxyList()); err != nil {
+		logger.Fatalf("Failed to set trusted proxies: %v", err)
 	}
 
+	e.Use(otelgin.Middleware(cfg.OtelServiceName), middleware.TraceHeaders(), middleware.Language())
+
+	// Connect to the databases
+	logger.Println("Connecting to databases...")
+	masterDB, replicas := connection.InitDB(*cfg)
+
+	// Repositories read through the first replica's handle; the router
+	// decides per query which replica, or the master, serves it
+	replicaDB := replicas[0].DB
+
+	// Apply pending migrations when not run as a separate deploy step,
+	// otherwise only make sure applied migrations have not been edited
+	migrationManager := migrations.NewMigrationManager(masterDB)
+	migrationManager.SetDriftPolicy(cfg.MigrationDriftPolicy)
+	migrationManager.SetLockTimeout(cfg.MigrationLockTimeout)
+	if cfg.MigrateOnBoot {
+		logger.Println("Running database migrations...")
+		if err := migrationManager.Migrate(context.Background()); err != nil {
+			logger.Fatalf("Failed to run migrations: %v", err)
+		}
+	} else if err := migrationManager.CheckDrift(context.Background()); err != nil {
+		logger.Fatalf("Migration drift detected: %v", err)
+	}
+
+	// Seeding on boot is a development convenience; other environments run
+	// `oms seed` explicitly
+	if sets := seed.ParseSets(cfg.SeedOnBoot); len(sets) > 0 {
+		if cfg.AppEnv != config.EnvDevelopment {
+			logger.Printf("Ignoring SEED_ON_BOOT outside the %s environment (APP_ENV=%q)", config.EnvDevelopment, cfg.AppEnv)
+		} else if err := seed.NewSeeder(masterDB).Run(context.Background(), sets...); err != nil {
+			logger.Fatalf("Failed to seed database: %v", err)
+		}
+	}
+
+	redisClient := connection.GetRedis(*cfg)
+
+	// Route replica reads to the master while the replica is down or lagging,
+	// and for users who just wrote
+	dbRouter, err := connection.NewRouter(masterDB, replicas, connection.NewRedisPinStore(redisClient), *cfg)
+	if err != nil {
+		logger.Fatalf("Failed to set up database routing: %v", err)
+	}
+	if err := dbRouter.Register(); err != nil {
+		logger.Fatalf("Failed to set up database routing: %v", err)
+	}
+
+	// Dependency checks backing the readiness probe
+	healthService := service.NewHealthService(repository.NewHealthRepository(masterDB, replicas, redisClient), *cfg)
+
+	// Deleted records are kept in the trash until the retention period ends
+	trashService := service.NewTrashService(repository.NewTrashRepository(masterDB, replicaDB), *cfg)
+
 	// Initialize routes
 	logger.Println("Initializing routes...")
-	routes.InitRoutes(e)
+	routes.InitRoutes(e, *cfg, masterDB, replicaDB, redisClient, healthService, trashService)
+
+	// Every request context derives from baseCtx so in-flight work can be
+	// cancelled if draining takes longer than the shutdown timeout.
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+
+	go dbRouter.Run(baseCtx)
+	if cfg.TrashPurgeInterval > 0 {
+		go service.RunTrashPurge(baseCtx, trashService, cfg.TrashPurgeInterval)
+	}
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: e,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
 	go func() {
@@ -53,24 +142,50 @@ func Serve(e *gin.Engine) {
 
 	logger.Printf("Received signal: %s", sig.String())
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownTimeout := cfg.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+
+	// Fail readiness first so load balancers stop routing new traffic here
+	healthService.MarkShuttingDown()
+	if cfg.ShutdownDrainDelay > 0 {
+		logger.Printf("Readiness set to failing, waiting %s before draining...", cfg.ShutdownDrainDelay)
+		time.Sleep(cfg.ShutdownDrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
-	// Shutdown server
+	// Stop accepting connections and wait for in-flight requests
 	logger.Println("Shutting down HTTP server...")
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Printf("Server shutdown error: %v", err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			logger.Println("Shutdown timed out, cancelling in-flight requests")
+		}
 	} else {
 		logger.Println("HTTP server stopped gracefully")
 	}
 
-	// Wait a moment for any pending operations
-	select {
-	case <-shutdownCtx.Done():
-		if errors.Is(context.DeadlineExceeded, shutdownCtx.Err()) {
-			logger.Println("Shutdown completed with timeout")
-		}
-	default:
+	// Abort whatever is still running so database calls return promptly
+	cancelBase()
+
+	// Close database connection pools
+	logger.Println("Closing database connections...")
+	connection.CloseDB(masterDB)
+	for _, replica := range replicas {
+		connection.CloseDB(replica.DB)
+	}
+	if err := redisClient.Close(); err != nil {
+		logger.Printf("Redis close error: %v", err)
+	}
+
+	// Flush buffered spans
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancelFlush()
+	if err := shutdownTracer(flushCtx); err != nil {
+		logger.Printf("Tracer shutdown error: %v", err)
 	}
 
 	logger.Println("OMS server terminated")
@@ -1,8 +1,18 @@
 package domain
 
-import "oms/types"
+import (
+	"context"
+	"oms/types"
+)
 
 type AuthService interface {
-	Login(loginRequest types.UserLoginRequest) (types.UserLoginResponse, error)
-	Logout(accessToken string) error
+	Login(ctx context.Context, loginRequest types.UserLoginRequest) (types.UserLoginResponse, error)
+	LoginMFA(ctx context.Context, request types.MFALoginRequest) (types.UserLoginResponse, error)
+	Logout(ctx context.Context, accessToken string) error
+	Signup(ctx context.Context, request types.SignupRequest) error
+	VerifyEmail(ctx context.Context, request types.VerifyEmailRequest) error
+	ResendVerification(ctx context.Context, request types.ResendVerificationRequest) error
+	ForgotPassword(ctx context.Context, request types.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, request types.ResetPasswordRequest) error
+	ChangePassword(ctx context.Context, userID int64, request types.ChangePasswordRequest) error
 }
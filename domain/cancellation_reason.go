@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"oms/model"
+	"oms/types"
+)
+
+type CancellationReasonRepository interface {
+	CreateCancellationReason(ctx context.Context, reason model.CancellationReason) error
+	GetCancellationReasonByID(ctx context.Context, id int64) (model.CancellationReason, error)
+	GetCancellationReasonByCode(ctx context.Context, code string) (model.CancellationReason, error)
+	GetAllCancellationReasons(ctx context.Context, limit, offset int) ([]model.CancellationReason, error)
+	UpdateCancellationReason(ctx context.Context, reason model.CancellationReason) error
+	DeleteCancellationReason(ctx context.Context, id int64) error
+}
+
+type CancellationReasonService interface {
+	CreateCancellationReason(ctx context.Context, reason types.CancellationReasonCreateRequest) error
+	GetCancellationReasonByID(ctx context.Context, id int64) (types.CancellationReasonResponse, error)
+	GetAllCancellationReasons(ctx context.Context, limit, offset int) ([]types.CancellationReasonResponse, error)
+	UpdateCancellationReason(ctx context.Context, reason types.CancellationReasonUpdateRequest) error
+	DeleteCancellationReason(ctx context.Context, id int64) error
+}
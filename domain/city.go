@@ -1,24 +1,25 @@
 package domain
 
 import (
+	"context"
 	"oms/model"
 	"oms/types"
 )
 
 type CityRepository interface {
-	CreateCity(city model.City) error
-	GetCityByID(id int64) (model.City, error)
-	GetAllCities(limit, offset int) ([]model.City, error)
-	GetCityByName(name string) (model.City, error)
-	UpdateCity(city model.City) error
-	DeleteCity(id int64) error
+	CreateCity(ctx context.Context, city model.City) error
+	GetCityByID(ctx context.Context, id int64) (model.City, error)
+	GetAllCities(ctx context.Context, limit, offset int) ([]model.City, error)
+	GetCityByName(ctx context.Context, name string) (model.City, error)
+	UpdateCity(ctx context.Context, city model.City) (int64, error)
+	DeleteCity(ctx context.Context, id int64) error
 }
 
 type CityService interface {
-	CreateCity(city types.CityCreateRequest) error
-	GetCityByID(id int64) (types.CityResponse, error)
-	GetAllCities(limit, offset int) ([]types.CityResponse, error)
-	GetCityByName(name string) (types.CityResponse, error)
-	UpdateCity(city types.CityUpdateRequest) error
-	DeleteCity(id int64) error
+	CreateCity(ctx context.Context, city types.CityCreateRequest) error
+	GetCityByID(ctx context.Context, id int64) (types.CityResponse, error)
+	GetAllCities(ctx context.Context, limit, offset int) ([]types.CityResponse, error)
+	GetCityByName(ctx context.Context, name string) (types.CityResponse, error)
+	UpdateCity(ctx context.Context, city types.CityUpdateRequest) (int64, error)
+	DeleteCity(ctx context.Context, id int64) error
 }
@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"context"
+	"oms/model"
+)
+
+type EmailVerificationTokenRepository interface {
+	CreateEmailVerificationToken(ctx context.Context, token model.EmailVerificationToken) error
+	ConsumeEmailVerificationToken(ctx context.Context, tokenHash string) (int64, error)
+	InvalidateUserTokens(ctx context.Context, userID int64) error
+}
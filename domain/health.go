@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"context"
+	"oms/types"
+	"time"
+)
+
+type HealthRepository interface {
+	PingMaster(ctx context.Context) error
+	PingRedis(ctx context.Context) error
+	ReplicaAddresses() []string
+	ReplicaLag(ctx context.Context, address string) (time.Duration, error)
+	PendingMigrations(ctx context.Context) ([]string, error)
+}
+
+type HealthService interface {
+	Liveness(ctx context.Context) types.LivenessResponse
+	Readiness(ctx context.Context) types.ReadinessResponse
+	MarkShuttingDown()
+}
@@ -1,23 +1,24 @@
 package domain
 
 import (
+	"context"
 	"oms/model"
 	"oms/types"
 )
 
 type ItemTypeRepository interface {
-	CreateItemType(itemType model.ItemType) error
-	GetItemTypeByID(id int64) (model.ItemType, error)
-	GetAllItemTypes(limit, offset int) ([]model.ItemType, error)
-	UpdateItemType(itemType model.ItemType) error
-	DeleteItemType(id int64) error
-	GetItemTypeByName(name string) (model.ItemType, error)
+	CreateItemType(ctx context.Context, itemType model.ItemType) error
+	GetItemTypeByID(ctx context.Context, id int64) (model.ItemType, error)
+	GetAllItemTypes(ctx context.Context, limit, offset int) ([]model.ItemType, error)
+	UpdateItemType(ctx context.Context, itemType model.ItemType) error
+	DeleteItemType(ctx context.Context, id int64) error
+	GetItemTypeByName(ctx context.Context, name string) (model.ItemType, error)
 }
 
 type ItemTypeService interface {
-	CreateItemType(itemType types.ItemTypeCreateRequest) error
-	GetItemTypeByID(id int64) (types.ItemTypeResponse, error)
-	GetAllItemTypes(limit, offset int) ([]types.ItemTypeResponse, error)
-	UpdateItemType(itemType types.ItemTypeUpdateRequest) error
-	DeleteItemType(id int64) error
+	CreateItemType(ctx context.Context, itemType types.ItemTypeCreateRequest) error
+	GetItemTypeByID(ctx context.Context, id int64) (types.ItemTypeResponse, error)
+	GetAllItemTypes(ctx context.Context, limit, offset int) ([]types.ItemTypeResponse, error)
+	UpdateItemType(ctx context.Context, itemType types.ItemTypeUpdateRequest) error
+	DeleteItemType(ctx context.Context, id int64) error
 }
@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"context"
+	"oms/model"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	CreateLoginAttempt(ctx context.Context, attempt model.LoginAttempt) error
+	CountFailuresByEmail(ctx context.Context, email string, since time.Time) (int64, error)
+	CountFailuresByIP(ctx context.Context, ipAddress string, since time.Time) (int64, error)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"oms/model"
+	"oms/types"
+)
+
+type MFARepository interface {
+	GetUserMFA(ctx context.Context, userID int64) (model.UserMFA, error)
+	SavePendingMFA(ctx context.Context, userID int64, secretCiphertext string) error
+	EnableMFA(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+	DeleteMFA(ctx context.Context, userID int64) error
+}
+
+type MFAService interface {
+	Enroll(ctx context.Context, userID int64) (types.MFAEnrollResponse, error)
+	ConfirmEnrollment(ctx context.Context, userID int64, request types.MFACodeRequest) (types.MFARecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID int64, request types.MFACodeRequest) error
+	Status(ctx context.Context, userID int64) (types.MFAStatusResponse, error)
+	Verify(ctx context.Context, userID int64, code, recoveryCode string) (bool, error)
+}
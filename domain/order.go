@@ -1,24 +1,25 @@
 package domain
 
 import (
+	"context"
 	"oms/model"
 	"oms/types"
 )
 
 type OrderRepository interface {
-	CreateOrder(order model.Order) error
-	GetOrderByConsignmentID(consignmentID string) (model.Order, error)
-	ListAllOrders(listReq types.OrderListRequest) ([]model.Order, model.Pagination, error)
-	UpdateOrder(order model.Order) error
-	UpdateOrderStatus(id int64, status string) error
-	DeleteOrder(id int64) error
+	CreateOrder(ctx context.Context, order model.Order) error
+	GetOrderByConsignmentID(ctx context.Context, consignmentID string) (model.Order, error)
+	ListAllOrders(ctx context.Context, listReq types.OrderListRequest) ([]model.Order, model.Pagination, error)
+	UpdateOrder(ctx context.Context, order model.Order) (int64, error)
+	DeleteOrder(ctx context.Context, id int64) error
 }
 
 type OrderService interface {
-	CreateOrder(order types.OrderCreateRequest) (types.OrderCreateResponse, error)
-	GetOrderByConsignmentID(consignmentID string, userId int64) (types.OrderResponse, error)
-	ListAllOrders(listReq types.OrderListRequest) (types.OrderListResponse, error)
-	UpdateOrder(order types.OrderUpdateRequest) error
-	UpdateOrderStatus(updateReq types.OrderStatusUpdateRequest, status string) error
-	DeleteOrder(consignmentID string, userId int64) error
+	CreateOrder(ctx context.Context, order types.OrderCreateRequest) (types.OrderCreateResponse, error)
+	GetOrderByConsignmentID(ctx context.Context, consignmentID string, userId int64) (types.OrderResponse, error)
+	ListAllOrders(ctx context.Context, listReq types.OrderListRequest) (types.OrderListResponse, error)
+	UpdateOrder(ctx context.Context, order types.OrderUpdateRequest) (int64, error)
+	PatchOrder(ctx context.Context, patchReq types.OrderPatchRequest) (types.OrderResponse, error)
+	CancelOrder(ctx context.Context, cancelReq types.OrderCancelRequest) (types.OrderResponse, error)
+	DeleteOrder(ctx context.Context, consignmentID string, userId int64) error
 }
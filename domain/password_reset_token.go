@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"context"
+	"oms/model"
+)
+
+type PasswordResetTokenRepository interface {
+	CreatePasswordResetToken(ctx context.Context, token model.PasswordResetToken) error
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string) (int64, error)
+	InvalidateUserTokens(ctx context.Context, userID int64) error
+}
@@ -1,23 +1,24 @@
 package domain
 
 import (
+	"context"
 	"oms/model"
 	"oms/types"
 )
 
 type StoreRepository interface {
-	CreateStore(store model.Store) error
-	GetStoreByID(id int64) (model.Store, error)
-	GetAllStores(limit, offset int) ([]model.Store, error)
-	GetStoreByName(name string) (model.Store, error)
-	UpdateStore(store model.Store) error
-	DeleteStore(id int64) error
+	CreateStore(ctx context.Context, store model.Store) error
+	GetStoreByID(ctx context.Context, id int64) (model.Store, error)
+	GetAllStores(ctx context.Context, limit, offset int) ([]model.Store, error)
+	GetStoreByName(ctx context.Context, name string) (model.Store, error)
+	UpdateStore(ctx context.Context, store model.Store) (int64, error)
+	DeleteStore(ctx context.Context, id int64) error
 }
 
 type StoreService interface {
-	CreateStore(store types.StoreCreateRequest) error
-	GetStoreByID(id int64) (types.StoreResponse, error)
-	GetAllStores(limit, offset int) ([]types.StoreResponse, error)
-	UpdateStore(store types.StoreUpdateRequest) error
-	DeleteStore(id int64) error
+	CreateStore(ctx context.Context, store types.StoreCreateRequest) error
+	GetStoreByID(ctx context.Context, id int64) (types.StoreResponse, error)
+	GetAllStores(ctx context.Context, limit, offset int) ([]types.StoreResponse, error)
+	UpdateStore(ctx context.Context, store types.StoreUpdateRequest) (int64, error)
+	DeleteStore(ctx context.Context, id int64) error
 }
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+	"oms/model"
+	"oms/types"
+	"time"
+)
+
+type TrashRepository interface {
+	ListDeleted(ctx context.Context, kind string, limit, offset int) ([]model.TrashedRecord, error)
+	Restore(ctx context.Context, kind string, id int64) error
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (map[string]int64, error)
+}
+
+type TrashService interface {
+	ListTrash(ctx context.Context, kind string, limit, offset int) ([]types.TrashedRecordResponse, error)
+	RestoreFromTrash(ctx context.Context, kind string, id int64) error
+	PurgeExpired(ctx context.Context) error
+}
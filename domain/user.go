@@ -1,25 +1,33 @@
 package domain
 
 import (
+	"context"
 	"oms/model"
 	"oms/types"
+	"time"
 )
 
 type UserRepository interface {
-	CreateUser(user model.User) error
-	GetUserByID(id int64) (model.User, error)
-	GetAllUsers(limit, offset int) ([]model.User, error)
-	UpdateUserEmail(user model.User) error
-	DeleteUser(id int64) error
-	GetUserByEmail(email string) (model.User, error)
+	CreateUser(ctx context.Context, user model.User) (model.User, error)
+	GetUserByID(ctx context.Context, id int64) (model.User, error)
+	GetAllUsers(ctx context.Context, limit, offset int) ([]model.User, error)
+	UpdateUserEmail(ctx context.Context, user model.User) error
+	DeleteUser(ctx context.Context, id int64) error
+	GetUserByEmail(ctx context.Context, email string) (model.User, error)
+	LockUser(ctx context.Context, id int64, until time.Time) (bool, error)
+	ResetLockout(ctx context.Context, id int64) error
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id int64) error
+	UpdateLanguage(ctx context.Context, id int64, language string) error
 }
 
 type UserService interface {
-	CreateUser(user types.UserCreateRequest) error
-	GetUserByID(id int64) (types.UserResponse, error)
-	GetAllUsers(limit, offset int) ([]types.UserResponse, error)
-	UpdateUserEmail(user types.UserUpdateRequest) error
-	DeleteUser(id int64) error
-	GetUserByEmail(email string) (types.UserResponse, error)
-	VerifyUserCredentials(email, password string) bool
+	CreateUser(ctx context.Context, user types.UserCreateRequest) error
+	GetUserByID(ctx context.Context, id int64) (types.UserResponse, error)
+	GetAllUsers(ctx context.Context, limit, offset int) ([]types.UserResponse, error)
+	UpdateUserEmail(ctx context.Context, user types.UserUpdateRequest) error
+	DeleteUser(ctx context.Context, id int64) error
+	GetUserByEmail(ctx context.Context, email string) (types.UserResponse, error)
+	VerifyUserCredentials(ctx context.Context, email, password string) bool
+	UpdateLanguage(ctx context.Context, userID int64, request types.UserLanguageRequest) error
 }
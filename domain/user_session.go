@@ -1,20 +1,23 @@
 package domain
 
 import (
+	"context"
 	"oms/model"
 	"oms/types"
 )
 
 type UserSessionRepository interface {
-	CreateUserSession(session model.UserSession) error
-	GetUserSessionByAccessToken(accessToken string) (model.UserSession, error)
-	DeleteExpiredSessions() error
-	InvalidateSession(tokenHash string) error
+	CreateUserSession(ctx context.Context, session model.UserSession) error
+	GetUserSessionByAccessToken(ctx context.Context, accessToken string) (model.UserSession, error)
+	DeleteExpiredSessions(ctx context.Context) error
+	InvalidateSession(ctx context.Context, tokenHash string) error
+	InvalidateUserSessions(ctx context.Context, userID int64) error
 }
 
 type UserSessionService interface {
-	CreateUserSession(userID int64) (model.UserSession, error)
-	ValidateSession(tokenHash string) (types.UserSessionResponse, error)
-	CleanupExpiredSessions() error
-	InvalidateSession(tokenHash string) error
+	CreateUserSession(ctx context.Context, userID int64, mfaVerified bool) (model.UserSession, error)
+	ValidateSession(ctx context.Context, tokenHash string) (types.UserSessionResponse, error)
+	CleanupExpiredSessions(ctx context.Context) error
+	InvalidateSession(ctx context.Context, tokenHash string) error
+	InvalidateUserSessions(ctx context.Context, userID int64) error
 }
@@ -1,27 +1,28 @@
 package domain
 
 import (
+	"context"
 	"oms/model"
 	"oms/types"
 )
 
 type ZoneRepository interface {
-	CreateZone(zone model.Zone) error
-	GetZoneByID(id int64) (model.Zone, error)
-	GetAllZones(limit, offset int) ([]model.Zone, error)
-	UpdateZone(zone model.Zone) error
-	DeleteZone(id int64) error
-	GetZoneByName(name string) (model.Zone, error)
-	GetZonesByCityID(cityID int64, limit, offset int) ([]model.Zone, error)
-	GetZoneByNameAndCityID(name string, cityID int64) (model.Zone, error)
-	CountZonesByCity(cityID int64) (int64, error)
+	CreateZone(ctx context.Context, zone model.Zone) error
+	GetZoneByID(ctx context.Context, id int64) (model.Zone, error)
+	GetAllZones(ctx context.Context, limit, offset int) ([]model.Zone, error)
+	UpdateZone(ctx context.Context, zone model.Zone) (int64, error)
+	DeleteZone(ctx context.Context, id int64) error
+	GetZoneByName(ctx context.Context, name string) (model.Zone, error)
+	GetZonesByCityID(ctx context.Context, cityID int64, limit, offset int) ([]model.Zone, error)
+	GetZoneByNameAndCityID(ctx context.Context, name string, cityID int64) (model.Zone, error)
+	CountZonesByCity(ctx context.Context, cityID int64) (int64, error)
 }
 
 type ZoneService interface {
-	CreateZone(zone types.ZoneCreateRequest) error
-	GetZoneByID(id int64) (types.ZoneResponse, error)
-	GetAllZones(limit, offset int) ([]types.ZoneResponse, error)
-	GetZonesByCityID(cityID int64, limit, offset int) ([]types.ZoneResponse, error)
-	UpdateZone(zone types.ZoneUpdateRequest) error
-	DeleteZone(id int64) error
+	CreateZone(ctx context.Context, zone types.ZoneCreateRequest) error
+	GetZoneByID(ctx context.Context, id int64) (types.ZoneResponse, error)
+	GetAllZones(ctx context.Context, limit, offset int) ([]types.ZoneResponse, error)
+	GetZonesByCityID(ctx context.Context, cityID int64, limit, offset int) ([]types.ZoneResponse, error)
+	UpdateZone(ctx context.Context, zone types.ZoneUpdateRequest) (int64, error)
+	DeleteZone(ctx context.Context, id int64) error
 }
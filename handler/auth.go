@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"math"
 	"net/http"
 	"oms/consts"
 	"oms/domain"
 	"oms/types"
 	"oms/utility"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,22 +23,39 @@ func NewAuthHandler(authService domain.AuthService) *AuthHandler {
 
 func (handler AuthHandler) Login(ctx *gin.Context) {
 	var req types.UserLoginRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	response, err := handler.authService.Login(req)
+	req.IPAddress = ctx.ClientIP()
+	req.UserAgent = ctx.Request.UserAgent()
+
+	response, err := handler.authService.Login(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if response.MFARequired {
+		utility.SendSuccessResponse(ctx, http.StatusOK, "MFA code required", response)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully logged in", response)
+}
+
+func (handler AuthHandler) LoginMFA(ctx *gin.Context) {
+	var req types.MFALoginRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	req.IPAddress = ctx.ClientIP()
+	req.UserAgent = ctx.Request.UserAgent()
+
+	response, err := handler.authService.LoginMFA(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "invalid email or password" {
-			utility.SendErrorResponse(ctx, http.StatusUnauthorized, "The user credentials were incorrect.", []any{err.Error()})
-			return
-		}
-		if err.Error() == "failed to create session" {
-			utility.SendErrorResponse(ctx, http.StatusInternalServerError, "failed to create session", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to login", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -49,15 +69,106 @@ func (handler AuthHandler) Logout(ctx *gin.Context) {
 		return
 	}
 
-	err := handler.authService.Logout(accessToken)
+	err := handler.authService.Logout(ctx.Request.Context(), accessToken)
 	if err != nil {
-		if err.Error() == "invalid access token" {
-			utility.SendErrorResponse(ctx, http.StatusUnauthorized, "invalid access token", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to logout", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully logged out", nil)
 }
+
+func (handler AuthHandler) Signup(ctx *gin.Context) {
+	var req types.SignupRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	err := handler.authService.Signup(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusCreated, "Successfully signed up, please check your email to verify your account", nil)
+}
+
+func (handler AuthHandler) VerifyEmail(ctx *gin.Context) {
+	var req types.VerifyEmailRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	err := handler.authService.VerifyEmail(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Email verified, you can now log in", nil)
+}
+
+func (handler AuthHandler) ResendVerification(ctx *gin.Context) {
+	var req types.ResendVerificationRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	err := handler.authService.ResendVerification(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusAccepted, "If an unverified account exists for this email, a verification link has been sent", nil)
+}
+
+func (handler AuthHandler) ForgotPassword(ctx *gin.Context) {
+	var req types.ForgotPasswordRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	err := handler.authService.ForgotPassword(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusAccepted, "If an account exists for this email, a reset link has been sent", nil)
+}
+
+func (handler AuthHandler) ResetPassword(ctx *gin.Context) {
+	var req types.ResetPasswordRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	err := handler.authService.ResetPassword(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Password has been reset, please log in again", nil)
+}
+
+func (handler AuthHandler) ChangePassword(ctx *gin.Context) {
+	var req types.ChangePasswordRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	userID := ctx.GetInt64(consts.UserIdKey)
+	err := handler.authService.ChangePassword(ctx.Request.Context(), userID, req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Password has been changed, please log in again", nil)
+}
+
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
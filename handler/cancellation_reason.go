@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+	"oms/domain"
+	"oms/types"
+	"oms/utility"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CancellationReasonHandler struct {
+	cancellationReasonService domain.CancellationReasonService
+}
+
+func NewCancellationReasonHandler(cancellationReasonService domain.CancellationReasonService) *CancellationReasonHandler {
+	return &CancellationReasonHandler{cancellationReasonService: cancellationReasonService}
+}
+
+func (handler CancellationReasonHandler) CreateCancellationReason(ctx *gin.Context) {
+	var req types.CancellationReasonCreateRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	err := handler.cancellationReasonService.CreateCancellationReason(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusCreated, "Successfully created cancellation reason", nil)
+}
+
+func (handler CancellationReasonHandler) GetCancellationReasonByID(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid cancellation reason ID format", err)
+		return
+	}
+
+	if id <= 0 {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Id should be positive", nil)
+		return
+	}
+
+	response, err := handler.cancellationReasonService.GetCancellationReasonByID(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched cancellation reason", response)
+}
+
+func (handler CancellationReasonHandler) GetAllCancellationReasons(ctx *gin.Context) {
+	limitStr := ctx.DefaultQuery("limit", "10")
+	offsetStr := ctx.DefaultQuery("offset", "0")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "invalid limit parameter", nil)
+		return
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "invalid offset parameter", nil)
+		return
+	}
+
+	responses, err := handler.cancellationReasonService.GetAllCancellationReasons(ctx.Request.Context(), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched cancellation reasons", responses)
+}
+
+func (handler CancellationReasonHandler) UpdateCancellationReason(ctx *gin.Context) {
+	var req types.CancellationReasonUpdateRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	err := handler.cancellationReasonService.UpdateCancellationReason(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully updated cancellation reason", nil)
+}
+
+func (handler CancellationReasonHandler) DeleteCancellationReason(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid cancellation reason ID format", err)
+		return
+	}
+
+	if id <= 0 {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Id should be positive", nil)
+		return
+	}
+
+	err = handler.cancellationReasonService.DeleteCancellationReason(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully deleted cancellation reason", nil)
+}
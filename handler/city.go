@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"oms/domain"
 	"oms/types"
@@ -9,7 +8,6 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type CityHandler struct {
@@ -22,23 +20,13 @@ func NewCityHandler(cityService domain.CityService) *CityHandler {
 
 func (handler CityHandler) CreateCity(ctx *gin.Context) {
 	var req types.CityCreateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	if req.BaseDeliveryFee < 0 {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "base_delivery_fee cannot be negative", nil)
-		return
-	}
-
-	err := handler.cityService.CreateCity(req)
+	err := handler.cityService.CreateCity(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "city with name '"+req.Name+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "city with name already exists", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to create city", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -49,7 +37,7 @@ func (handler CityHandler) GetCityByID(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid city ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid city ID format", err)
 		return
 	}
 
@@ -58,15 +46,12 @@ func (handler CityHandler) GetCityByID(ctx *gin.Context) {
 		return
 	}
 
-	response, err := handler.cityService.GetCityByID(id)
+	response, err := handler.cityService.GetCityByID(ctx.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "city with ID "+idStr+" not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "city not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch city", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
+	utility.SetETag(ctx, response.Version)
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched city", response)
 }
 
@@ -86,9 +71,9 @@ func (handler CityHandler) GetAllCities(ctx *gin.Context) {
 		return
 	}
 
-	responses, err := handler.cityService.GetAllCities(limit, offset)
+	responses, err := handler.cityService.GetAllCities(ctx.Request.Context(), limit, offset)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch cities", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -97,26 +82,23 @@ func (handler CityHandler) GetAllCities(ctx *gin.Context) {
 
 func (handler CityHandler) UpdateCity(ctx *gin.Context) {
 	var req types.CityUpdateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	if req.BaseDeliveryFee < 0 {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "base_delivery_fee cannot be negative", nil)
+	version, ok := utility.IfMatchVersion(ctx)
+	if !ok {
 		return
 	}
+	req.Version = version
 
-	err := handler.cityService.UpdateCity(req)
+	version, err := handler.cityService.UpdateCity(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "city with name '"+req.Name+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "city with name already exists", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to update city", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
+	utility.SetETag(ctx, version)
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully updated city", nil)
 }
 
@@ -124,7 +106,7 @@ func (handler CityHandler) DeleteCity(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid city ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid city ID format", err)
 		return
 	}
 
@@ -133,9 +115,9 @@ func (handler CityHandler) DeleteCity(ctx *gin.Context) {
 		return
 	}
 
-	err = handler.cityService.DeleteCity(id)
+	err = handler.cityService.DeleteCity(ctx.Request.Context(), id)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to delete city", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -149,15 +131,12 @@ func (handler CityHandler) GetCityByName(ctx *gin.Context) {
 		return
 	}
 
-	response, err := handler.cityService.GetCityByName(name)
+	response, err := handler.cityService.GetCityByName(ctx.Request.Context(), name)
 	if err != nil {
-		if err.Error() == "city with name '"+name+"' not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "City not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch city", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
+	utility.SetETag(ctx, response.Version)
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched city", response)
 }
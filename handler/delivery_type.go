@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"oms/domain"
 	"oms/types"
@@ -9,7 +8,6 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type DeliveryTypeHandler struct {
@@ -22,22 +20,13 @@ func NewDeliveryTypeHandler(deliveryTypeService domain.DeliveryTypeService) *Del
 
 func (handler DeliveryTypeHandler) CreateDeliveryType(ctx *gin.Context) {
 	var req types.DeliveryTypeCreateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.deliveryTypeService.CreateDeliveryType(req)
+	err := handler.deliveryTypeService.CreateDeliveryType(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "delivery type with name '"+req.Name+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "delivery type with name already exists", []any{err.Error()})
-			return
-		}
-		if err.Error() == "delivery type name cannot be empty" {
-			utility.SendErrorResponse(ctx, http.StatusBadRequest, "delivery type name cannot be empty", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to create delivery type", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -48,7 +37,7 @@ func (handler DeliveryTypeHandler) GetDeliveryTypeByID(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid delivery type ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid delivery type ID format", err)
 		return
 	}
 
@@ -57,13 +46,9 @@ func (handler DeliveryTypeHandler) GetDeliveryTypeByID(ctx *gin.Context) {
 		return
 	}
 
-	response, err := handler.deliveryTypeService.GetDeliveryTypeByID(id)
+	response, err := handler.deliveryTypeService.GetDeliveryTypeByID(ctx.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "delivery type with ID "+idStr+" not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "delivery type not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch delivery type", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched delivery type", response)
@@ -85,9 +70,9 @@ func (handler DeliveryTypeHandler) GetAllDeliveryTypes(ctx *gin.Context) {
 		return
 	}
 
-	responses, err := handler.deliveryTypeService.GetAllDeliveryTypes(limit, offset)
+	responses, err := handler.deliveryTypeService.GetAllDeliveryTypes(ctx.Request.Context(), limit, offset)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch delivery types", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -96,22 +81,13 @@ func (handler DeliveryTypeHandler) GetAllDeliveryTypes(ctx *gin.Context) {
 
 func (handler DeliveryTypeHandler) UpdateDeliveryType(ctx *gin.Context) {
 	var req types.DeliveryTypeUpdateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.deliveryTypeService.UpdateDeliveryType(req)
+	err := handler.deliveryTypeService.UpdateDeliveryType(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "delivery type with name '"+req.Name+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "delivery type with name already exists", []any{err.Error()})
-			return
-		}
-		if err.Error() == "delivery type name cannot be empty" {
-			utility.SendErrorResponse(ctx, http.StatusBadRequest, "delivery type name cannot be empty", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to update delivery type", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -122,7 +98,7 @@ func (handler DeliveryTypeHandler) DeleteDeliveryType(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid delivery type ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid delivery type ID format", err)
 		return
 	}
 
@@ -131,13 +107,9 @@ func (handler DeliveryTypeHandler) DeleteDeliveryType(ctx *gin.Context) {
 		return
 	}
 
-	err = handler.deliveryTypeService.DeleteDeliveryType(id)
+	err = handler.deliveryTypeService.DeleteDeliveryType(ctx.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "delivery type does not exist" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "delivery type not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to delete delivery type", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"oms/consts"
+	"oms/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	healthService domain.HealthService
+}
+
+func NewHealthHandler(healthService domain.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+func (handler HealthHandler) Livez(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, handler.healthService.Liveness(ctx.Request.Context()))
+}
+
+func (handler HealthHandler) Readyz(ctx *gin.Context) {
+	response := handler.healthService.Readiness(ctx.Request.Context())
+	if response.Status != consts.HealthStatusOK {
+		ctx.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
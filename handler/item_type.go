@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"oms/domain"
 	"oms/types"
@@ -9,7 +8,6 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type ItemTypeHandler struct {
@@ -22,22 +20,13 @@ func NewItemTypeHandler(itemTypeService domain.ItemTypeService) *ItemTypeHandler
 
 func (handler ItemTypeHandler) CreateItemType(ctx *gin.Context) {
 	var req types.ItemTypeCreateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.itemTypeService.CreateItemType(req)
+	err := handler.itemTypeService.CreateItemType(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "item type with name '"+req.Name+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "item type with name already exists", []any{err.Error()})
-			return
-		}
-		if err.Error() == "item type name cannot be empty" {
-			utility.SendErrorResponse(ctx, http.StatusBadRequest, "item type name cannot be empty", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to create item type", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -48,7 +37,7 @@ func (handler ItemTypeHandler) GetItemTypeByID(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid item type ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid item type ID format", err)
 		return
 	}
 
@@ -57,13 +46,9 @@ func (handler ItemTypeHandler) GetItemTypeByID(ctx *gin.Context) {
 		return
 	}
 
-	response, err := handler.itemTypeService.GetItemTypeByID(id)
+	response, err := handler.itemTypeService.GetItemTypeByID(ctx.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "item type with ID "+idStr+" not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "item type not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch item type", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched item type", response)
@@ -85,9 +70,9 @@ func (handler ItemTypeHandler) GetAllItemTypes(ctx *gin.Context) {
 		return
 	}
 
-	responses, err := handler.itemTypeService.GetAllItemTypes(limit, offset)
+	responses, err := handler.itemTypeService.GetAllItemTypes(ctx.Request.Context(), limit, offset)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch item types", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -96,22 +81,13 @@ func (handler ItemTypeHandler) GetAllItemTypes(ctx *gin.Context) {
 
 func (handler ItemTypeHandler) UpdateItemType(ctx *gin.Context) {
 	var req types.ItemTypeUpdateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.itemTypeService.UpdateItemType(req)
+	err := handler.itemTypeService.UpdateItemType(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "item type with name '"+req.Name+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "item type with name already exists", []any{err.Error()})
-			return
-		}
-		if err.Error() == "item type name cannot be empty" {
-			utility.SendErrorResponse(ctx, http.StatusBadRequest, "item type name cannot be empty", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to update item type", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -122,7 +98,7 @@ func (handler ItemTypeHandler) DeleteItemType(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid item type ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid item type ID format", err)
 		return
 	}
 
@@ -131,13 +107,9 @@ func (handler ItemTypeHandler) DeleteItemType(ctx *gin.Context) {
 		return
 	}
 
-	err = handler.itemTypeService.DeleteItemType(id)
+	err = handler.itemTypeService.DeleteItemType(ctx.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "item type does not exist" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "item type not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to delete item type", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
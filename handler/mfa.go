@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"oms/consts"
+	"oms/domain"
+	"oms/types"
+	"oms/utility"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	mfaService domain.MFAService
+}
+
+func NewMFAHandler(mfaService domain.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+func (handler MFAHandler) Status(ctx *gin.Context) {
+	userID := ctx.GetInt64(consts.UserIdKey)
+	response, err := handler.mfaService.Status(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched mfa status", response)
+}
+
+func (handler MFAHandler) Enroll(ctx *gin.Context) {
+	userID := ctx.GetInt64(consts.UserIdKey)
+	response, err := handler.mfaService.Enroll(ctx.Request.Context(), userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Add the secret to your authenticator app and confirm with a code", response)
+}
+
+func (handler MFAHandler) ConfirmEnrollment(ctx *gin.Context) {
+	var req types.MFACodeRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	userID := ctx.GetInt64(consts.UserIdKey)
+	response, err := handler.mfaService.ConfirmEnrollment(ctx.Request.Context(), userID, req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "MFA enabled, store the recovery codes somewhere safe", response)
+}
+
+func (handler MFAHandler) Disable(ctx *gin.Context) {
+	var req types.MFACodeRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	userID := ctx.GetInt64(consts.UserIdKey)
+	err := handler.mfaService.Disable(ctx.Request.Context(), userID, req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "MFA disabled", nil)
+}
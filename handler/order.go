@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"errors"
+	"encoding/json"
 	"net/http"
 	"oms/consts"
 	"oms/domain"
@@ -9,9 +9,11 @@ import (
 	"oms/utility"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+// mergePatchContentType is the media type of a JSON Merge Patch (RFC 7386)
+const mergePatchContentType = "application/merge-patch+json"
+
 type OrderHandler struct {
 	orderService domain.OrderService
 }
@@ -22,13 +24,7 @@ func NewOrderHandler(orderService domain.OrderService) *OrderHandler {
 
 func (handler OrderHandler) CreateOrder(ctx *gin.Context) {
 	var req types.OrderCreateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusUnprocessableEntity, "Unable the bind request", nil)
-		return
-	}
-
-	if validationErrors := req.Validate(); validationErrors != nil {
-		utility.SendErrorResponse(ctx, http.StatusUnprocessableEntity, "Please fix the given errors", validationErrors.Errors)
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
@@ -43,16 +39,9 @@ func (handler OrderHandler) CreateOrder(ctx *gin.Context) {
 		utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Please login first...", nil)
 	}
 
-	response, err := handler.orderService.CreateOrder(req)
+	response, err := handler.orderService.CreateOrder(ctx.Request.Context(), req)
 	if err != nil {
-		// Handle specific validation errors
-		if err.Error() == "invalid store_id: store not found" {
-			utility.SendErrorResponse(ctx, http.StatusUnprocessableEntity, "Please fix the given errors", map[string][]string{
-				"store_id": {"The store field is required", "Wrong Store selected"},
-			})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to create order", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -79,21 +68,13 @@ func (handler OrderHandler) GetOrderByConsignmentID(ctx *gin.Context) {
 		utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Please login first...", nil)
 	}
 
-	response, err := handler.orderService.GetOrderByConsignmentID(consignmentID, userId)
+	response, err := handler.orderService.GetOrderByConsignmentID(ctx.Request.Context(), consignmentID, userId)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) ||
-			err.Error() == "order with consignment ID '"+consignmentID+"' not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "Order not found", []any{err.Error()})
-			return
-		}
-		if err.Error() == "unauthorized" {
-			utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", nil)
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch order", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
+	utility.SetETag(ctx, response.Version)
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Order successfully fetched", response)
 }
 
@@ -101,8 +82,7 @@ func (handler OrderHandler) ListAllOrders(ctx *gin.Context) {
 	var req types.OrderListRequest
 
 	// Parse query parameters
-	if err := ctx.ShouldBindQuery(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", []any{err.Error()})
+	if !utility.BindQuery(ctx, &req) {
 		return
 	}
 
@@ -125,9 +105,9 @@ func (handler OrderHandler) ListAllOrders(ctx *gin.Context) {
 		utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Please login first...", nil)
 	}
 
-	response, err := handler.orderService.ListAllOrders(req)
+	response, err := handler.orderService.ListAllOrders(ctx.Request.Context(), req)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch orders", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -136,11 +116,16 @@ func (handler OrderHandler) ListAllOrders(ctx *gin.Context) {
 
 func (handler OrderHandler) UpdateOrder(ctx *gin.Context) {
 	var req types.OrderUpdateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
+	version, ok := utility.IfMatchVersion(ctx)
+	if !ok {
+		return
+	}
+	req.Version = version
+
 	if req.ConsignmentID == "" {
 		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Consignment ID is required", nil)
 		return
@@ -157,26 +142,77 @@ func (handler OrderHandler) UpdateOrder(ctx *gin.Context) {
 		utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Please login first...", nil)
 	}
 
-	err := handler.orderService.UpdateOrder(req)
+	version, err := handler.orderService.UpdateOrder(ctx.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) ||
-			err.Error() == "order with consignment ID '"+req.ConsignmentID+"' not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "Order not found", []any{err.Error()})
-			return
-		}
-		if err.Error() == "unauthorized" {
-			utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", nil)
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to update order", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
+	utility.SetETag(ctx, version)
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully updated order", nil)
 }
 
+// PatchOrder changes some fields of an order with a JSON Merge Patch: the
+// members of the body replace those fields, null clears one, and fields left
+// out keep their value. It replies with the order as changed.
+func (handler OrderHandler) PatchOrder(ctx *gin.Context) {
+	consignmentID := ctx.Param("consignment_id")
+	if consignmentID == "" {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Consignment ID is required", nil)
+		return
+	}
+
+	if contentType := ctx.ContentType(); contentType != mergePatchContentType && contentType != gin.MIMEJSON {
+		utility.SendErrorResponse(ctx, http.StatusUnsupportedMediaType, "Send the patch as application/merge-patch+json", nil)
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&patch); err != nil || patch == nil {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "The patch must be a JSON object", nil)
+		return
+	}
+
+	version, ok := utility.IfMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	req := types.OrderPatchRequest{
+		ConsignmentID: consignmentID,
+		Version:       version,
+		Patch:         patch,
+	}
+
+	// Extract user ID from JWT token context (assuming middleware sets this)
+	userID, exists := ctx.Get(consts.UserIdKey)
+	if exists {
+		if id, ok := userID.(int64); ok {
+			req.UserId = id
+		}
+	}
+	if !exists {
+		utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Please login first...", nil)
+		return
+	}
+
+	response, err := handler.orderService.PatchOrder(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SetETag(ctx, response.Version)
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully updated order", response)
+}
+
+// CancelOrder cancels an order for a reason from the managed list. An order
+// cancelled already is returned as it is.
 func (handler OrderHandler) CancelOrder(ctx *gin.Context) {
-	var req types.OrderStatusUpdateRequest
+	var req types.OrderCancelRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
 
 	consignmentID := ctx.Param("consignment_id")
 	if consignmentID == "" {
@@ -195,24 +231,17 @@ func (handler OrderHandler) CancelOrder(ctx *gin.Context) {
 	}
 	if !exists {
 		utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Please login first...", nil)
+		return
 	}
 
-	err := handler.orderService.UpdateOrderStatus(req, consts.OrderStatusCancelled)
+	response, err := handler.orderService.CancelOrder(ctx.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) ||
-			err.Error() == "order with consignment ID '"+req.ConsignmentID+"' not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "Order not found", []any{err.Error()})
-			return
-		}
-		if err.Error() == "unauthorized" {
-			utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", nil)
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to update order status", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	utility.SendSuccessResponse(ctx, http.StatusOK, "Order Cancelled Successfully", nil)
+	utility.SetETag(ctx, response.Version)
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Order Cancelled Successfully", response)
 }
 
 func (handler OrderHandler) DeleteOrder(ctx *gin.Context) {
@@ -235,18 +264,9 @@ func (handler OrderHandler) DeleteOrder(ctx *gin.Context) {
 		utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Please login first...", nil)
 	}
 
-	err := handler.orderService.DeleteOrder(consignmentID, userId)
+	err := handler.orderService.DeleteOrder(ctx.Request.Context(), consignmentID, userId)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) ||
-			err.Error() == "order with consignment ID '"+consignmentID+"' not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "Order not found", []any{err.Error()})
-			return
-		}
-		if err.Error() == "unauthorized" {
-			utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Unauthorized", nil)
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to delete order", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"oms/domain"
 	"oms/types"
@@ -9,7 +8,6 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type StoreHandler struct {
@@ -22,18 +20,13 @@ func NewStoreHandler(storeService domain.StoreService) *StoreHandler {
 
 func (handler StoreHandler) CreateStore(ctx *gin.Context) {
 	var req types.StoreCreateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.storeService.CreateStore(req)
+	err := handler.storeService.CreateStore(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "store with name '"+req.Name+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "store with name already exists", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to create store", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -44,7 +37,7 @@ func (handler StoreHandler) GetStoreByID(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid store ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid store ID format", err)
 		return
 	}
 
@@ -53,15 +46,12 @@ func (handler StoreHandler) GetStoreByID(ctx *gin.Context) {
 		return
 	}
 
-	response, err := handler.storeService.GetStoreByID(id)
+	response, err := handler.storeService.GetStoreByID(ctx.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "store with ID "+idStr+" not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "store not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch store", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
+	utility.SetETag(ctx, response.Version)
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched store", response)
 }
 
@@ -81,9 +71,9 @@ func (handler StoreHandler) GetAllStores(ctx *gin.Context) {
 		return
 	}
 
-	responses, err := handler.storeService.GetAllStores(limit, offset)
+	responses, err := handler.storeService.GetAllStores(ctx.Request.Context(), limit, offset)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch stores", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -92,21 +82,23 @@ func (handler StoreHandler) GetAllStores(ctx *gin.Context) {
 
 func (handler StoreHandler) UpdateStore(ctx *gin.Context) {
 	var req types.StoreUpdateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.storeService.UpdateStore(req)
+	version, ok := utility.IfMatchVersion(ctx)
+	if !ok {
+		return
+	}
+	req.Version = version
+
+	version, err := handler.storeService.UpdateStore(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "store with name '"+req.Name+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "store with name already exists", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to update store", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
+	utility.SetETag(ctx, version)
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully updated store", nil)
 }
 
@@ -114,7 +106,7 @@ func (handler StoreHandler) DeleteStore(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid store ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid store ID format", err)
 		return
 	}
 
@@ -123,9 +115,9 @@ func (handler StoreHandler) DeleteStore(ctx *gin.Context) {
 		return
 	}
 
-	err = handler.storeService.DeleteStore(id)
+	err = handler.storeService.DeleteStore(ctx.Request.Context(), id)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to delete store", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
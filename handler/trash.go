@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"oms/domain"
+	"oms/utility"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	trashService domain.TrashService
+}
+
+func NewTrashHandler(trashService domain.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+func (handler TrashHandler) ListTrash(ctx *gin.Context) {
+	limitStr := ctx.DefaultQuery("limit", "10")
+	offsetStr := ctx.DefaultQuery("offset", "0")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "invalid limit parameter", nil)
+		return
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "invalid offset parameter", nil)
+		return
+	}
+
+	responses, err := handler.trashService.ListTrash(ctx.Request.Context(), ctx.Param("kind"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched deleted records", responses)
+}
+
+func (handler TrashHandler) RestoreFromTrash(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid ID format", err)
+		return
+	}
+
+	if id <= 0 {
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Id should be positive", nil)
+		return
+	}
+
+	err = handler.trashService.RestoreFromTrash(ctx.Request.Context(), ctx.Param("kind"), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully restored record", nil)
+}
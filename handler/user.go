@@ -1,15 +1,15 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
+	"oms/consts"
 	"oms/domain"
+	"oms/i18n"
 	"oms/types"
 	"oms/utility"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type UserHandler struct {
@@ -22,22 +22,13 @@ func NewUserHandler(userService domain.UserService) *UserHandler {
 
 func (handler UserHandler) CreateUser(ctx *gin.Context) {
 	var req types.UserCreateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.userService.CreateUser(req)
+	err := handler.userService.CreateUser(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "user with email '"+req.Email+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "user with email already exists", []any{err.Error()})
-			return
-		}
-		if err.Error() == "email cannot be empty" {
-			utility.SendErrorResponse(ctx, http.StatusBadRequest, "email cannot be empty", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to create user", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -48,7 +39,7 @@ func (handler UserHandler) GetUserByID(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID format", err)
 		return
 	}
 
@@ -57,13 +48,9 @@ func (handler UserHandler) GetUserByID(ctx *gin.Context) {
 		return
 	}
 
-	response, err := handler.userService.GetUserByID(id)
+	response, err := handler.userService.GetUserByID(ctx.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "user with ID "+idStr+" not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "user not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch user", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched user", response)
@@ -85,9 +72,9 @@ func (handler UserHandler) GetAllUsers(ctx *gin.Context) {
 		return
 	}
 
-	responses, err := handler.userService.GetAllUsers(limit, offset)
+	responses, err := handler.userService.GetAllUsers(ctx.Request.Context(), limit, offset)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch users", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -96,33 +83,47 @@ func (handler UserHandler) GetAllUsers(ctx *gin.Context) {
 
 func (handler UserHandler) UpdateUserEmail(ctx *gin.Context) {
 	var req types.UserUpdateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.userService.UpdateUserEmail(req)
+	err := handler.userService.UpdateUserEmail(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "user with email '"+req.Email+"' already exists" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "user with email already exists", []any{err.Error()})
-			return
-		}
-		if err.Error() == "email cannot be empty" {
-			utility.SendErrorResponse(ctx, http.StatusBadRequest, "email cannot be empty", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to update user", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully updated user", nil)
 }
 
+func (handler UserHandler) UpdateLanguage(ctx *gin.Context) {
+	var req types.UserLanguageRequest
+	if !utility.BindJSON(ctx, &req) {
+		return
+	}
+
+	userID := ctx.GetInt64(consts.UserIdKey)
+	err := handler.userService.UpdateLanguage(ctx.Request.Context(), userID, req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Answer in the language just chosen
+	lang := req.Language
+	if lang == "" {
+		lang = i18n.Negotiate(ctx.GetHeader("Accept-Language"))
+	}
+	ctx.Request = ctx.Request.WithContext(i18n.WithLanguage(ctx.Request.Context(), lang))
+
+	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully updated language", nil)
+}
+
 func (handler UserHandler) DeleteUser(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID format", err)
 		return
 	}
 
@@ -131,13 +132,9 @@ func (handler UserHandler) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
-	err = handler.userService.DeleteUser(id)
+	err = handler.userService.DeleteUser(ctx.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "user does not exist" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "user not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to delete user", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -151,13 +148,9 @@ func (handler UserHandler) GetUserByEmail(ctx *gin.Context) {
 		return
 	}
 
-	response, err := handler.userService.GetUserByEmail(email)
+	response, err := handler.userService.GetUserByEmail(ctx.Request.Context(), email)
 	if err != nil {
-		if err.Error() == "user with email '"+email+"' not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "user not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch user", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched user", response)
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"oms/domain"
 	"oms/types"
@@ -9,7 +8,6 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type ZoneHandler struct {
@@ -22,22 +20,13 @@ func NewZoneHandler(zoneService domain.ZoneService) *ZoneHandler {
 
 func (handler ZoneHandler) CreateZone(ctx *gin.Context) {
 	var req types.ZoneCreateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.zoneService.CreateZone(req)
+	err := handler.zoneService.CreateZone(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "zone with name '"+req.Name+"' already exists in this city" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "zone with name already exists in this city", []any{err.Error()})
-			return
-		}
-		if err.Error() == "city with ID "+strconv.FormatInt(req.CityID, 10)+" does not exist" {
-			utility.SendErrorResponse(ctx, http.StatusBadRequest, "invalid city ID", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to create zone", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -48,7 +37,7 @@ func (handler ZoneHandler) GetZoneByID(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid zone ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid zone ID format", err)
 		return
 	}
 
@@ -57,15 +46,12 @@ func (handler ZoneHandler) GetZoneByID(ctx *gin.Context) {
 		return
 	}
 
-	response, err := handler.zoneService.GetZoneByID(id)
+	response, err := handler.zoneService.GetZoneByID(ctx.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == "zone with ID "+idStr+" not found" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "zone not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch zone", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
+	utility.SetETag(ctx, response.Version)
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully fetched zone", response)
 }
 
@@ -85,9 +71,9 @@ func (handler ZoneHandler) GetAllZones(ctx *gin.Context) {
 		return
 	}
 
-	responses, err := handler.zoneService.GetAllZones(limit, offset)
+	responses, err := handler.zoneService.GetAllZones(ctx.Request.Context(), limit, offset)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch zones", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -98,7 +84,7 @@ func (handler ZoneHandler) GetZonesByCity(ctx *gin.Context) {
 	cityIDStr := ctx.Param("cityId")
 	cityID, err := strconv.ParseInt(cityIDStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid city ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid city ID format", err)
 		return
 	}
 
@@ -122,13 +108,9 @@ func (handler ZoneHandler) GetZonesByCity(ctx *gin.Context) {
 		return
 	}
 
-	responses, err := handler.zoneService.GetZonesByCityID(cityID, limit, offset)
+	responses, err := handler.zoneService.GetZonesByCityID(ctx.Request.Context(), cityID, limit, offset)
 	if err != nil {
-		if err.Error() == "city with ID "+cityIDStr+" does not exist" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "city not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to fetch zones", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -137,21 +119,23 @@ func (handler ZoneHandler) GetZonesByCity(ctx *gin.Context) {
 
 func (handler ZoneHandler) UpdateZone(ctx *gin.Context) {
 	var req types.ZoneUpdateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", []any{err.Error()})
+	if !utility.BindJSON(ctx, &req) {
 		return
 	}
 
-	err := handler.zoneService.UpdateZone(req)
+	version, ok := utility.IfMatchVersion(ctx)
+	if !ok {
+		return
+	}
+	req.Version = version
+
+	version, err := handler.zoneService.UpdateZone(ctx.Request.Context(), req)
 	if err != nil {
-		if err.Error() == "zone with name '"+req.Name+"' already exists in this city" {
-			utility.SendErrorResponse(ctx, http.StatusConflict, "zone with name already exists in this city", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to update zone", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
+	utility.SetETag(ctx, version)
 	utility.SendSuccessResponse(ctx, http.StatusOK, "Successfully updated zone", nil)
 }
 
@@ -159,7 +143,7 @@ func (handler ZoneHandler) DeleteZone(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid zone ID format", []any{err.Error()})
+		utility.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid zone ID format", err)
 		return
 	}
 
@@ -168,13 +152,9 @@ func (handler ZoneHandler) DeleteZone(ctx *gin.Context) {
 		return
 	}
 
-	err = handler.zoneService.DeleteZone(id)
+	err = handler.zoneService.DeleteZone(ctx.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "zone does not exist" {
-			utility.SendErrorResponse(ctx, http.StatusNotFound, "zone not found", []any{err.Error()})
-			return
-		}
-		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Unable to delete zone", []any{err.Error()})
+		ctx.Error(err)
 		return
 	}
 
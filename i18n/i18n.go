@@ -0,0 +1,141 @@
+// Package i18n translates the text the API shows to people: response
+// messages, validation errors and notifications. Messages are looked up by
+// their English text, so English needs no catalog and a message missing
+// from a catalog falls back to English.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// English is the language of the message keys and the default
+	English = "en"
+	// Bangla is spoken by most merchants' staff and delivery agents
+	Bangla = "bn"
+	// Default is used when neither the user nor the request names a
+	// supported language
+	Default = English
+)
+
+// Supported lists the languages with a catalog, in order of preference
+var Supported = []string{English, Bangla}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogs maps each language to its translations, keyed by English text
+var catalogs = loadCatalogs()
+
+// Text is a word or phrase passed as an argument to Sprintf that is
+// translated too, such as the name of an entity
+type Text string
+
+func loadCatalogs() map[string]map[string]string {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		// The embed pattern guarantees the directory exists
+		panic(err)
+	}
+
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("invalid catalog %s: %v", file.Name(), err))
+		}
+		loaded[strings.TrimSuffix(file.Name(), ".json")] = catalog
+	}
+
+	return loaded
+}
+
+// IsSupported reports whether lang is one of the Supported languages
+func IsSupported(lang string) bool {
+	for _, supported := range Supported {
+		if lang == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// T translates message into lang, or returns it unchanged when there is no
+// translation
+func T(lang, message string) string {
+	if translated, ok := catalogs[lang][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf translates format into lang and formats it with args. Arguments
+// of type Text are translated as well. Translations may reorder the
+// arguments with explicit indexes such as %[2]d.
+func Sprintf(lang, format string, args ...any) string {
+	translatedArgs := make([]any, len(args))
+	for i, arg := range args {
+		if text, ok := arg.(Text); ok {
+			arg = T(lang, string(text))
+		}
+		translatedArgs[i] = arg
+	}
+
+	return fmt.Sprintf(T(lang, format), translatedArgs...)
+}
+
+// Negotiate picks the supported language the client prefers most in an
+// Accept-Language header, or Default when it names none of them
+func Negotiate(acceptLanguage string) string {
+	best, bestQuality := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		// Regional variants such as bn-BD count as the language itself
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !IsSupported(lang) {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = lang, quality
+		}
+	}
+
+	return best
+}
+
+type languageKey struct{}
+
+// WithLanguage returns a copy of ctx that carries lang
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// FromContext returns the language carried by ctx, or Default
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	return Default
+}
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"oms/cli"
 	"oms/container"
+	"os"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] != "serve" {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
 	e := gin.New()
 	e.Use(gin.Recovery())
 	container.Serve(e)
@@ -1,15 +1,18 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
+	"oms/apperror"
 	"oms/consts"
 	"oms/domain"
+	"oms/i18n"
 	"oms/utility"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Auth(userSessionSvc domain.UserSessionService) gin.HandlerFunc {
+func Auth(userSessionSvc domain.UserSessionService, jwtSecret string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
@@ -23,33 +26,37 @@ func Auth(userSessionSvc domain.UserSessionService) gin.HandlerFunc {
 			userToken = authHeader[7:]
 		}
 
-		if userToken == "" {
-			utility.SendErrorResponse(ctx, http.StatusForbidden, "Unauthorized", nil)
-			ctx.Abort()
-			return
-		}
-
-		_, err := userSessionSvc.ValidateSession(userToken)
+		// Why a token was rejected stays in the log; clients only learn that
+		// it was
+		claims, err := utility.VerifyJWT(jwtSecret, userToken)
 		if err != nil {
-			utility.SendErrorResponse(ctx, http.StatusForbidden, "Unauthorized", []any{err.Error()})
+			log.Printf("Rejected access token: %v", err)
+		}
+		if userToken == "" || err != nil || claims == nil || claims.UserID == 0 {
+			ctx.Error(apperror.Unauthorized("invalid or expired token"))
 			ctx.Abort()
 			return
 		}
 
-		claims, err := utility.VerifyJWT(userToken)
+		// The user ID travels in the request context so database routing can
+		// keep this user's reads on the master right after they write
+		ctx.Request = ctx.Request.WithContext(utility.ContextWithUserID(ctx.Request.Context(), claims.UserID))
+
+		session, err := userSessionSvc.ValidateSession(ctx.Request.Context(), userToken)
 		if err != nil {
-			utility.SendErrorResponse(ctx, http.StatusForbidden, "Unauthorized", []any{err.Error()})
+			ctx.Error(err)
 			ctx.Abort()
 			return
 		}
 
-		if claims == nil || claims.UserID == 0 {
-			utility.SendErrorResponse(ctx, http.StatusForbidden, "Unauthorized", nil)
-			ctx.Abort()
-			return
+		// A language the user chose wins over what their client asks for
+		if session.Language != "" {
+			ctx.Request = ctx.Request.WithContext(i18n.WithLanguage(ctx.Request.Context(), session.Language))
 		}
+
 		ctx.Set(consts.UserIdKey, claims.UserID)
 		ctx.Set(consts.AccessTokenKey, userToken)
+		ctx.Set(consts.MFAVerifiedKey, session.MFAVerified)
 		ctx.Next()
 	}
 }
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"oms/apperror"
+	"oms/consts"
+	"oms/domain"
+	"oms/i18n"
+	"oms/utility"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole lets the request through only when the authenticated user has
+// one of roles. The role is looked up on every request so a change takes
+// effect without waiting for tokens to expire. Use it after Auth.
+func RequireRole(userService domain.UserService, roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt64(consts.UserIdKey)
+		if userID == 0 {
+			utility.SendErrorResponse(ctx, http.StatusUnauthorized, "Please login first...", nil)
+			ctx.Abort()
+			return
+		}
+
+		user, err := userService.GetUserByID(ctx.Request.Context(), userID)
+		if err != nil {
+			if apperror.IsNotFound(err) {
+				err = apperror.Forbidden("Forbidden")
+			}
+			ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		if !slices.Contains(roles, user.Role) {
+			utility.SendErrorResponse(ctx, http.StatusForbidden, "Forbidden", []any{i18n.Sprintf(utility.Language(ctx), "requires role %s", roles[0])})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireMFA lets the request through only when the session was started
+// with a second factor. Use it after Auth.
+func RequireMFA() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !ctx.GetBool(consts.MFAVerifiedKey) {
+			utility.SendErrorResponse(ctx, http.StatusForbidden, "Forbidden", []any{i18n.Sprintf(utility.Language(ctx), "requires a session verified with mfa, enable mfa and log in again")})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
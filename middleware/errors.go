@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"oms/apperror"
+	"oms/i18n"
+	"oms/utility"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusByKind maps the kinds of apperror to response codes
+var statusByKind = []struct {
+	kind   error
+	status int
+}{
+	{apperror.ErrNotFound, http.StatusNotFound},
+	{apperror.ErrConflict, http.StatusConflict},
+	{apperror.ErrForbidden, http.StatusForbidden},
+	{apperror.ErrValidation, http.StatusUnprocessableEntity},
+	{apperror.ErrUnauthorized, http.StatusUnauthorized},
+	{apperror.ErrLocked, http.StatusLocked},
+	{apperror.ErrTooManyRequests, http.StatusTooManyRequests},
+}
+
+// Errors writes the response for the last error a handler or middleware
+// added with ctx.Error, unless a response was already written. Errors of a
+// known apperror kind get its status and client message; anything else is
+// logged and answered with a bare 500, so database and other internal errors
+// never reach clients. Use it before Timeout so a timeout is answered with 504.
+func Errors() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		err := ctx.Errors.Last().Err
+
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			if retryAfter := appErr.RetryAfter(); retryAfter > 0 {
+				ctx.Header("Retry-After", ceilSeconds(retryAfter))
+			}
+			lang := utility.Language(ctx)
+			utility.SendErrorResponse(ctx, errorStatus(appErr), appErr.MessageIn(lang), translateDetails(lang, appErr.Details()))
+			return
+		}
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			utility.SendErrorResponse(ctx, http.StatusGatewayTimeout, "Request timed out", nil)
+			return
+		}
+
+		log.Printf("%s %s failed: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+		utility.SendErrorResponse(ctx, http.StatusInternalServerError, "Something went wrong, please try again later", nil)
+	}
+}
+
+// translateDetails translates messages per field; other details are sent as
+// they are
+func translateDetails(lang string, details any) any {
+	fields, ok := details.(map[string][]string)
+	if !ok {
+		return details
+	}
+
+	translated := make(map[string][]string, len(fields))
+	for field, messages := range fields {
+		for _, message := range messages {
+			translated[field] = append(translated[field], i18n.T(lang, message))
+		}
+	}
+	return translated
+}
+
+func errorStatus(err *apperror.Error) int {
+	for _, entry := range statusByKind {
+		if err.Kind() == entry.kind {
+			return entry.status
+		}
+	}
+	return http.StatusInternalServerError
+}
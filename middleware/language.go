@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"oms/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Language picks the language of the response from the Accept-Language
+// header and carries it in the request context. Auth replaces it with the
+// user's own preference when they have set one.
+func Language() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		lang := i18n.Negotiate(ctx.GetHeader("Accept-Language"))
+		ctx.Request = ctx.Request.WithContext(i18n.WithLanguage(ctx.Request.Context(), lang))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"oms/consts"
+	"oms/ratelimit"
+	"oms/utility"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit limits requests with defaultPolicy, or with the policy listed in
+// overrides for the route, keyed by method and full route path such as
+// "POST /api/v1/orders". Requests are counted per authenticated user, otherwise
+// per client IP, so use it after Auth on authenticated routes. Every response carries X-RateLimit-* headers; a request over the
+// limit gets 429 with Retry-After. When the limiter itself fails the request
+// is let through.
+func RateLimit(limiter ratelimit.Limiter, defaultPolicy ratelimit.Policy, overrides map[string]ratelimit.Policy) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		policy := defaultPolicy
+		if override, ok := overrides[ctx.Request.Method+" "+ctx.FullPath()]; ok {
+			policy = override
+		}
+
+		result, err := limiter.Allow(ctx.Request.Context(), rateLimitSubject(ctx), policy)
+		if err != nil {
+			log.Printf("Rate limit check failed: %v", err)
+			ctx.Next()
+			return
+		}
+
+		header := ctx.Writer.Header()
+		header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("X-RateLimit-Reset", ceilSeconds(result.ResetAfter))
+
+		if !result.Allowed {
+			header.Set("Retry-After", ceilSeconds(result.ResetAfter))
+			utility.SendErrorResponse(ctx, http.StatusTooManyRequests, "Too many requests, please try again later", nil)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// rateLimitSubject names who the request is counted against
+func rateLimitSubject(ctx *gin.Context) string {
+	if userID, ok := ctx.Get(consts.UserIdKey); ok {
+		if id, ok := userID.(int64); ok && id != 0 {
+			return "user:" + strconv.FormatInt(id, 10)
+		}
+	}
+
+	return "ip:" + ctx.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"oms/utility"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout bounds the request context so repository calls are cancelled once
+// the deadline passes. Routes listed in overrides, keyed by their full route
+// path, use their own duration instead of the default. A non-positive
+// duration disables the deadline for that route.
+func Timeout(defaultTimeout time.Duration, overrides map[string]time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		timeout := defaultTimeout
+		if override, ok := overrides[ctx.FullPath()]; ok {
+			timeout = override
+		}
+
+		if timeout <= 0 {
+			ctx.Next()
+			return
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Next()
+
+		if errors.Is(reqCtx.Err(), context.DeadlineExceeded) && !ctx.Writer.Written() {
+			utility.SendErrorResponse(ctx, http.StatusGatewayTimeout, "Request timed out", nil)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// TraceHeaders echoes the active trace context back to the caller as a
+// traceparent response header so a request can be found in the collector.
+func TraceHeaders() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		otel.GetTextMapPropagator().Inject(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Writer.Header()))
+		ctx.Next()
+	}
+}
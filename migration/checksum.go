@@ -0,0 +1,104 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"oms/model"
+	"strings"
+)
+
+const (
+	// DriftPolicyFail refuses to continue when an applied migration changed
+	DriftPolicyFail = "fail"
+	// DriftPolicyWarn logs changed migrations and carries on
+	DriftPolicyWarn = "warn"
+)
+
+// ChecksumMismatch describes an applied migration whose up file no longer
+// matches the checksum recorded when it ran
+type ChecksumMismatch struct {
+	Version  string
+	Recorded string
+	Current  string
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyChecksums compares every applied migration against its current file.
+// Records written before checksums existed are backfilled with the current
+// checksum so later edits are caught.
+func (m *MigrationManager) VerifyChecksums(ctx context.Context) ([]ChecksumMismatch, error) {
+	migrations, err := m.LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	appliedVersions, err := m.getAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []ChecksumMismatch
+	for _, migration := range migrations {
+		record, ok := appliedVersions[migration.Version]
+		if !ok {
+			continue
+		}
+
+		if record.Checksum == "" {
+			err := m.db.WithContext(ctx).Model(&model.MigrationRecord{}).
+				Where("version = ?", migration.Version).
+				Update("checksum", migration.Checksum).Error
+			if err != nil {
+				return nil, fmt.Errorf("failed to backfill checksum for %s: %w", migration.Version, err)
+			}
+			log.Printf("Recorded checksum for previously applied migration: %s", migration.Version)
+			continue
+		}
+
+		if record.Checksum != migration.Checksum {
+			mismatches = append(mismatches, ChecksumMismatch{
+				Version:  migration.Version,
+				Recorded: record.Checksum,
+				Current:  migration.Checksum,
+			})
+		}
+	}
+
+	return mismatches, nil
+}
+
+// CheckDrift verifies checksums and applies the configured drift policy
+func (m *MigrationManager) CheckDrift(ctx context.Context) error {
+	if err := m.ensureMigrationsTable(ctx); err != nil {
+		return err
+	}
+
+	mismatches, err := m.VerifyChecksums(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	versions := make([]string, 0, len(mismatches))
+	for _, mismatch := range mismatches {
+		log.Printf("Migration %s was modified after it was applied (recorded %s, current %s)",
+			mismatch.Version, mismatch.Recorded, mismatch.Current)
+		versions = append(versions, mismatch.Version)
+	}
+
+	if m.driftPolicy == DriftPolicyWarn {
+		return nil
+	}
+
+	return fmt.Errorf("applied migrations have been modified: %s", strings.Join(versions, ", "))
+}
@@ -0,0 +1,156 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// migrationLockKey identifies the advisory lock shared by every process that
+// runs migrations against the same database ("oms_mig" as an integer)
+const migrationLockKey int64 = 0x6f6d735f6d6967
+
+const (
+	// DefaultLockTimeout is how long to wait for another migration run to finish
+	DefaultLockTimeout = 5 * time.Minute
+
+	lockPollInterval   = time.Second
+	lockReportInterval = 10 * time.Second
+)
+
+// lockHolder describes the session currently holding the migration lock
+type lockHolder struct {
+	PID             int
+	ApplicationName string
+	ClientAddr      string
+	State           string
+	BackendStart    time.Time
+}
+
+// SetLockTimeout sets how long a run waits for the migration lock before
+// giving up. Zero falls back to DefaultLockTimeout.
+func (m *MigrationManager) SetLockTimeout(timeout time.Duration) {
+	m.lockTimeout = timeout
+}
+
+// withLock runs fn while holding a session level advisory lock so that only
+// one process migrates the database at a time. The lock lives on a dedicated
+// connection and is released when fn returns, or by PostgreSQL if the
+// process dies.
+func (m *MigrationManager) withLock(ctx context.Context, fn func() error) error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reserve connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if err := m.acquireLock(ctx, conn); err != nil {
+		return err
+	}
+
+	defer func() {
+		// The run context may already be cancelled; the unlock must still go out
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if _, err := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			log.Printf("Failed to release migration lock: %v", err)
+		}
+	}()
+
+	return fn()
+}
+
+// acquireLock polls pg_try_advisory_lock until it succeeds or the lock
+// timeout expires, logging who holds the lock while it waits
+func (m *MigrationManager) acquireLock(ctx context.Context, conn *sql.Conn) error {
+	timeout := m.lockTimeout
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+
+	lockCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	var lastReport time.Time
+	for {
+		var acquired bool
+		err := conn.QueryRowContext(lockCtx, "SELECT pg_try_advisory_lock($1)", migrationLockKey).Scan(&acquired)
+		if err != nil && lockCtx.Err() == nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if acquired {
+			if !lastReport.IsZero() {
+				log.Printf("Acquired migration lock after %s", time.Since(started).Round(time.Second))
+			}
+			return nil
+		}
+
+		if lockCtx.Err() == nil && time.Since(lastReport) >= lockReportInterval {
+			m.reportLockHolder(lockCtx, conn)
+			lastReport = time.Now()
+		}
+
+		select {
+		case <-lockCtx.Done():
+			if errors.Is(lockCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+				return fmt.Errorf("timed out after %s waiting for migration lock held by another process", timeout)
+			}
+			return fmt.Errorf("stopped waiting for migration lock: %w", ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func (m *MigrationManager) reportLockHolder(ctx context.Context, conn *sql.Conn) {
+	holder, err := m.lockHolder(ctx, conn)
+	if err != nil {
+		log.Printf("Waiting for migration lock (could not look up holder: %v)", err)
+		return
+	}
+	if holder == nil {
+		log.Printf("Waiting for migration lock")
+		return
+	}
+
+	log.Printf("Waiting for migration lock held by pid %d (application=%q client=%s state=%s connected since %s)",
+		holder.PID, holder.ApplicationName, holder.ClientAddr, holder.State,
+		holder.BackendStart.Format(time.RFC3339))
+}
+
+// lockHolder looks up the session holding the migration lock. A bigint
+// advisory key is split across classid (high half) and objid (low half).
+func (m *MigrationManager) lockHolder(ctx context.Context, conn *sql.Conn) (*lockHolder, error) {
+	const query = `
+		SELECT a.pid, COALESCE(a.application_name, ''), COALESCE(host(a.client_addr), 'local'),
+			COALESCE(a.state, ''), a.backend_start
+		FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory'
+			AND l.granted
+			AND l.objsubid = 1
+			AND l.classid = ($1::bigint >> 32)::oid
+			AND l.objid = ($1::bigint & 4294967295)::oid
+		LIMIT 1`
+
+	var holder lockHolder
+	err := conn.QueryRowContext(ctx, query, migrationLockKey).Scan(
+		&holder.PID, &holder.ApplicationName, &holder.ClientAddr, &holder.State, &holder.BackendStart)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &holder, nil
+}
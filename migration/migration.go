@@ -2,18 +2,21 @@ package migrations
 
 import (
 	"context"
+	"embed"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"oms/model"
 	"os"
 	"path/filepath"
-	"runtime"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -21,232 +24,314 @@ const (
 	migrationTimeout = 30 * time.Minute
 )
 
-func getSQLDir() string {
-	// Try multiple possible paths for better Docker compatibility
-	possiblePaths := []string{
-		// Path relative to the current file (your original approach)
-		func() string {
-			_, filename, _, _ := runtime.Caller(0)
-			return filepath.Join(filepath.Dir(filename), "sql")
-		}(),
-
-		// Path relative to working directory (Docker container)
-		"./migration/sql",
-		"/app/migration/sql",
-
-		// Path relative to executable
-		func() string {
-			ex, err := os.Executable()
-			if err != nil {
-				return ""
-			}
-			return filepath.Join(filepath.Dir(ex), "migration", "sql")
-		}(),
-	}
-
-	// Try each path and use the first one that exists
-	for _, path := range possiblePaths {
-		if path == "" {
-			continue
-		}
+// DefaultSQLDir is where migration files live in the source tree. New
+// migrations are created here; at runtime the embedded copy is used.
+const DefaultSQLDir = "migration/sql"
 
-		if info, err := os.Stat(path); err == nil && info.IsDir() {
-			log.Printf("Using SQL migration directory: %s", path)
-			return path
-		}
-		log.Printf("Tried SQL directory path: %s (not found)", path)
-	}
+//go:embed sql/*.sql
+var embeddedSQL embed.FS
 
-	// Fallback to original approach
-	_, filename, _, _ := runtime.Caller(0)
-	sqlDir := filepath.Join(filepath.Dir(filename), "sql")
-	log.Printf("Falling back to SQL directory: %s", sqlDir)
-	return sqlDir
+// Migration represents a single database migration discovered as a pair of
+// NNNN_name.up.sql and NNNN_name.down.sql files
+type Migration struct {
+	Version  string
+	UpFile   string
+	DownFile string
+	Checksum string
 }
 
-// Migration represents a single database migration
-type Migration struct {
-	Version string
-	Up      func(context.Context, *gorm.DB) error
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+	Version   string
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool
 }
 
-//// TableName sets the table name for MigrationRecord
-//func (MigrationRecord) TableName() string {
-//	return "schema_migrations"
-//}
+const (
+	directionUp   = "up"
+	directionDown = "down"
+)
+
+// NoTransactionDirective, placed in the comments at the top of a migration
+// file, runs that file outside a transaction. Statements such as CREATE INDEX
+// CONCURRENTLY need it; a failure part way through is not rolled back.
+const NoTransactionDirective = "-- oms:no-transaction"
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
 
 // MigrationManager handles database migrations
 type MigrationManager struct {
-	db     *gorm.DB
-	sqlDir string
+	db          *gorm.DB
+	files       fs.FS
+	driftPolicy string
+	lockTimeout time.Duration
 }
 
-// NewMigrationManager creates a new migration manager
+// NewMigrationManager creates a migration manager reading the migrations
+// embedded in the binary
 func NewMigrationManager(db *gorm.DB) *MigrationManager {
+	files, err := fs.Sub(embeddedSQL, "sql")
+	if err != nil {
+		// The embed pattern guarantees the directory exists
+		panic(err)
+	}
+
 	return &MigrationManager{
-		db:     db,
-		sqlDir: getSQLDir(),
+		db:          db,
+		files:       files,
+		driftPolicy: DriftPolicyFail,
+		lockTimeout: DefaultLockTimeout,
 	}
 }
 
-// SetSQLDir allows overriding the default SQL directory
+// SetSQLDir reads migrations from a directory on disk instead of the
+// embedded copy, which is useful while iterating on a new migration
 func (m *MigrationManager) SetSQLDir(dir string) {
-	m.sqlDir = dir
+	m.files = os.DirFS(dir)
 }
 
-// createMigrationFunc creates a migration function for a given version
-func (m *MigrationManager) createMigrationFunc(version string) func(context.Context, *gorm.DB) error {
-	return func(ctx context.Context, db *gorm.DB) error {
-		return m.applyMigration(ctx, version, db)
-	}
+// SetDriftPolicy controls what happens when an applied migration file has
+// changed since it ran; see DriftPolicyFail and DriftPolicyWarn
+func (m *MigrationManager) SetDriftPolicy(policy string) {
+	m.driftPolicy = policy
 }
 
-// applyMigration applies a single migration by reading and executing SQL file
-func (m *MigrationManager) applyMigration(ctx context.Context, version string, db *gorm.DB) error {
-	statements, err := m.readSQLFile(version)
+// LoadMigrations discovers migration files in the SQL directory, sorted by version
+func (m *MigrationManager) LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.files, ".")
 	if err != nil {
-		return fmt.Errorf("failed to read migration %s: %w", version, err)
+		return nil, fmt.Errorf("failed to read migration files: %w", err)
 	}
 
-	for i, stmt := range statements {
-		if err := m.execSQL(ctx, db, stmt); err != nil {
-			return fmt.Errorf("failed to execute statement %d in migration %s: %w", i+1, version, err)
+	byVersion := make(map[string]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			if strings.HasSuffix(entry.Name(), ".sql") {
+				log.Printf("Ignoring migration file with unexpected name: %s", entry.Name())
+			}
+			continue
+		}
+
+		version := match[1] + "_" + match[2]
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version}
+			byVersion[version] = migration
+		}
+
+		if match[3] == directionUp {
+			migration.UpFile = entry.Name()
+		} else {
+			migration.DownFile = entry.Name()
 		}
 	}
 
-	return nil
-}
+	migrations := make([]Migration, 0, len(byVersion))
+	seenNumbers := make(map[string]string, len(byVersion))
+	for version, migration := range byVersion {
+		if migration.UpFile == "" {
+			return nil, fmt.Errorf("migration %s has no up file", version)
+		}
+		if migration.DownFile == "" {
+			return nil, fmt.Errorf("migration %s has no down file", version)
+		}
+
+		number := versionNumber(version)
+		if other, ok := seenNumbers[number]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version number %s", other, version, number)
+		}
+		seenNumbers[number] = version
 
-// readSQLFile reads and parses SQL file into individual statements
-func (m *MigrationManager) readSQLFile(version string) ([]string, error) {
-	filename := fmt.Sprintf("%s.sql", version)
-	filePath := filepath.Join(m.sqlDir, filename)
+		content, err := fs.ReadFile(m.files, migration.UpFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", migration.UpFile, err)
+		}
+		migration.Checksum = checksum(content)
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("migration file %s does not exist", filePath)
+		migrations = append(migrations, *migration)
 	}
 
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL file %s: %w", filePath, err)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// runMigration executes one direction of a migration and the bookkeeping that
+// goes with it, inside a single transaction unless the file opts out
+func (m *MigrationManager) runMigration(ctx context.Context, migration Migration, direction string, bookkeeping func(tx *gorm.DB) error) error {
+	filename := migration.UpFile
+	if direction == directionDown {
+		filename = migration.DownFile
 	}
 
-	// Parse SQL statements
-	statements := m.parseSQLStatements(string(content))
-	if len(statements) == 0 {
-		return nil, fmt.Errorf("no valid SQL statements found in %s", filename)
+	content, statements, err := m.readSQLFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read migration %s: %w", migration.Version, err)
 	}
 
-	return statements, nil
-}
+	if !hasNoTransactionDirective(content) {
+		return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := m.applyMigration(ctx, filename, content, statements, tx); err != nil {
+				return err
+			}
 
-func (m *MigrationManager) parseSQLStatements(content string) []string {
-	var statements []string
-	var currentStatement strings.Builder
-	inParentheses := false
+			return bookkeeping(tx)
+		})
+	}
 
-	lines := strings.Split(content, "\n")
+	log.Printf("Running %s outside a transaction", filename)
+	db := m.db.WithContext(ctx)
+	if err := m.applyMigration(ctx, filename, content, statements, db); err != nil {
+		return err
+	}
 
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
+	return bookkeeping(db)
+}
 
-		// Skip comment lines
-		if strings.HasPrefix(trimmed, "--") || trimmed == "" {
-			continue
+// applyMigration executes the statements of a migration file in order
+func (m *MigrationManager) applyMigration(ctx context.Context, filename, content string, statements []Statement, db *gorm.DB) error {
+	for _, stmt := range statements {
+		if err := m.execSQL(ctx, db, stmt); err != nil {
+			return describeStatementError(filename, content, stmt, err)
 		}
+	}
 
-		// Check if we're entering or exiting parentheses
-		if strings.Contains(trimmed, "(") {
-			inParentheses = true
-		}
-		if strings.Contains(trimmed, ")") {
-			inParentheses = false
-		}
+	return nil
+}
 
-		// Add line to current statement
-		currentStatement.WriteString(trimmed)
-		currentStatement.WriteString(" ")
+// readSQLFile reads a migration file and splits it into individual statements
+func (m *MigrationManager) readSQLFile(filename string) (string, []Statement, error) {
+	raw, err := fs.ReadFile(m.files, filename)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read SQL file %s: %w", filename, err)
+	}
+	content := string(raw)
 
-		// If we find a semicolon and we're not inside parentheses, finalize the statement
-		if strings.Contains(trimmed, ";") && !inParentheses {
-			stmt := strings.TrimSpace(currentStatement.String())
-			if stmt != "" {
-				statements = append(statements, stmt)
-			}
-			currentStatement.Reset()
-		}
+	statements, err := SplitStatements(content)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if len(statements) == 0 {
+		return "", nil, fmt.Errorf("no valid SQL statements found in %s", filename)
 	}
 
-	// Add any remaining statement
-	if currentStatement.Len() > 0 {
-		stmt := strings.TrimSpace(currentStatement.String())
-		if stmt != "" {
-			statements = append(statements, stmt)
+	return content, statements, nil
+}
+
+// hasNoTransactionDirective reports whether NoTransactionDirective appears in
+// the comment lines at the top of a migration file
+func hasNoTransactionDirective(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			return false
+		}
+		if line == NoTransactionDirective {
+			return true
 		}
 	}
 
-	return statements
+	return false
 }
 
-// execSQL executes a SQL statement with proper error handling
-func (m *MigrationManager) execSQL(ctx context.Context, db *gorm.DB, sql string) error {
+// execSQL executes a single SQL statement
+func (m *MigrationManager) execSQL(ctx context.Context, db *gorm.DB, stmt Statement) error {
 	// Create a timeout context for the SQL execution
 	execCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	result := db.WithContext(execCtx).Exec(sql)
-	if result.Error != nil {
-		var pqErr *pq.Error
-		if errors.As(result.Error, &pqErr) {
-			// Handle specific PostgreSQL errors
-			switch pqErr.Code {
-			case "42P07": // relation already exists
-				log.Printf("Relation already exists, skipping: %s", sql[:min(50, len(sql))])
-				return nil
-			case "42701": // duplicate column
-				log.Printf("Column already exists, skipping: %s", sql[:min(50, len(sql))])
-				return nil
-			case "42P16": // index already exists
-				log.Printf("Index already exists, skipping: %s", sql[:min(50, len(sql))])
-				return nil
-			default:
-				return fmt.Errorf("postgres error %s: %s", pqErr.Code, pqErr.Message)
-			}
-		}
-		return fmt.Errorf("SQL execution failed: %w", result.Error)
+	return db.WithContext(execCtx).Exec(stmt.SQL).Error
+}
+
+// describeStatementError points a failed statement back at its file and line,
+// using the error position reported by PostgreSQL when there is one
+func describeStatementError(filename, content string, stmt Statement, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return fmt.Errorf("%s:%d: %w", filename, stmt.Line, err)
 	}
 
-	log.Printf("Successfully executed SQL statement")
-	return nil
+	line, column := stmt.Line, 0
+	if pgErr.Position > 0 {
+		line, column = positionInFile(content, stmt, int(pgErr.Position))
+	}
+
+	location := fmt.Sprintf("%s:%d", filename, line)
+	if column > 0 {
+		location = fmt.Sprintf("%s:%d", location, column)
+	}
+
+	message := fmt.Sprintf("%s: postgres error %s: %s", location, pgErr.Code, pgErr.Message)
+	if pgErr.Detail != "" {
+		message += " (detail: " + pgErr.Detail + ")"
+	}
+	if pgErr.Hint != "" {
+		message += " (hint: " + pgErr.Hint + ")"
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
 }
 
 // getAppliedMigrations retrieves all applied migrations from database
-func (m *MigrationManager) getAppliedMigrations(ctx context.Context) (map[string]bool, error) {
+func (m *MigrationManager) getAppliedMigrations(ctx context.Context) (map[string]model.MigrationRecord, error) {
 	var appliedMigrations []model.MigrationRecord
 
+	if !m.db.WithContext(ctx).Migrator().HasTable(&model.MigrationRecord{}) {
+		return map[string]model.MigrationRecord{}, nil
+	}
+
 	result := m.db.WithContext(ctx).Find(&appliedMigrations)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to fetch applied migrations: %w", result.Error)
 	}
 
-	appliedVersions := make(map[string]bool, len(appliedMigrations))
+	appliedVersions := make(map[string]model.MigrationRecord, len(appliedMigrations))
 	for _, migration := range appliedMigrations {
-		appliedVersions[migration.Version] = true
+		appliedVersions[migration.Version] = migration
 	}
 
 	return appliedVersions, nil
 }
 
 // recordMigration saves a migration record to the database
-func (m *MigrationManager) recordMigration(ctx context.Context, tx *gorm.DB, version string) error {
+func (m *MigrationManager) recordMigration(ctx context.Context, tx *gorm.DB, migration Migration) error {
 	record := model.MigrationRecord{
-		Version:   version,
+		Version:   migration.Version,
+		Checksum:  migration.Checksum,
 		AppliedAt: time.Now(),
 	}
 
 	if err := tx.WithContext(ctx).Create(&record).Error; err != nil {
-		return fmt.Errorf("failed to record migration %s: %w", version, err)
+		return fmt.Errorf("failed to record migration %s: %w", migration.Version, err)
+	}
+
+	return nil
+}
+
+// removeMigrationRecord deletes a migration record from the database
+func (m *MigrationManager) removeMigrationRecord(ctx context.Context, tx *gorm.DB, version string) error {
+	if err := tx.WithContext(ctx).Where("version = ?", version).Delete(&model.MigrationRecord{}).Error; err != nil {
+		return fmt.Errorf("failed to remove migration record %s: %w", version, err)
+	}
+
+	return nil
+}
+
+// ensureMigrationsTable creates the migrations table when it does not exist yet
+func (m *MigrationManager) ensureMigrationsTable(ctx context.Context) error {
+	if err := m.db.WithContext(ctx).AutoMigrate(&model.MigrationRecord{}); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
 	return nil
@@ -254,13 +339,33 @@ func (m *MigrationManager) recordMigration(ctx context.Context, tx *gorm.DB, ver
 
 // Migrate runs all pending migrations
 func (m *MigrationManager) Migrate(ctx context.Context) error {
+	return m.Up(ctx, 0)
+}
+
+// Up applies up to n pending migrations in version order, or all of them when n is zero
+func (m *MigrationManager) Up(ctx context.Context, n int) error {
+	return m.withLock(ctx, func() error {
+		return m.up(ctx, n)
+	})
+}
+
+func (m *MigrationManager) up(ctx context.Context, n int) error {
 	// Set up timeout for the entire migration process
 	migrationCtx, cancel := context.WithTimeout(ctx, migrationTimeout)
 	defer cancel()
 
-	// Ensure migrations table exists
-	if err := m.db.WithContext(migrationCtx).AutoMigrate(&model.MigrationRecord{}); err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
+	if err := m.ensureMigrationsTable(migrationCtx); err != nil {
+		return err
+	}
+
+	migrations, err := m.LoadMigrations()
+	if err != nil {
+		return err
+	}
+
+	// Refuse to build on top of migrations that were edited after they ran
+	if err := m.CheckDrift(migrationCtx); err != nil {
+		return err
 	}
 
 	// Get applied migrations
@@ -269,46 +374,269 @@ func (m *MigrationManager) Migrate(ctx context.Context) error {
 		return err
 	}
 
-	// Sort migrations by version
-	migrations := make([]Migration, len(AllMigrations))
-	copy(migrations, AllMigrations)
-	sort.Slice(migrations, func(i, j int) bool {
-		return migrations[i].Version < migrations[j].Version
+	applied := 0
+	for _, migration := range migrations {
+		if n > 0 && applied >= n {
+			break
+		}
+
+		if _, ok := appliedVersions[migration.Version]; ok {
+			continue
+		}
+
+		if err := m.apply(migrationCtx, migration); err != nil {
+			return err
+		}
+		applied++
+	}
+
+	if applied == 0 {
+		log.Printf("No pending migrations")
+		return nil
+	}
+
+	log.Printf("Applied %d migration(s)", applied)
+	return nil
+}
+
+// apply runs one migration up and records it
+func (m *MigrationManager) apply(ctx context.Context, migration Migration) error {
+	log.Printf("Applying migration: %s", migration.Version)
+
+	err := m.runMigration(ctx, migration, directionUp, func(tx *gorm.DB) error {
+		return m.recordMigration(ctx, tx, migration)
 	})
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", migration.Version, err)
+	}
 
-	// Apply pending migrations
-	for _, migration := range migrations {
-		if appliedVersions[migration.Version] {
-			log.Printf("Skipping already applied migration: %s", migration.Version)
+	log.Printf("Successfully applied migration: %s", migration.Version)
+	return nil
+}
+
+// Down rolls back the n most recently applied migrations
+func (m *MigrationManager) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("number of migrations to roll back must be positive")
+	}
+
+	return m.withLock(ctx, func() error {
+		_, err := m.down(ctx, n)
+		return err
+	})
+}
+
+// down rolls back up to n applied migrations, newest first, and returns the
+// ones it rolled back
+func (m *MigrationManager) down(ctx context.Context, n int) ([]Migration, error) {
+	migrationCtx, cancel := context.WithTimeout(ctx, migrationTimeout)
+	defer cancel()
+
+	if err := m.ensureMigrationsTable(migrationCtx); err != nil {
+		return nil, err
+	}
+
+	migrations, err := m.LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	appliedVersions, err := m.getAppliedMigrations(migrationCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledBack []Migration
+	for i := len(migrations) - 1; i >= 0 && len(rolledBack) < n; i-- {
+		migration := migrations[i]
+		if _, ok := appliedVersions[migration.Version]; !ok {
 			continue
 		}
 
-		log.Printf("Applying migration: %s", migration.Version)
+		log.Printf("Rolling back migration: %s", migration.Version)
 
-		// Execute migration in transaction
-		err := m.db.WithContext(migrationCtx).Transaction(func(tx *gorm.DB) error {
-			// Apply the migration
-			if err := migration.Up(migrationCtx, tx); err != nil {
-				return fmt.Errorf("migration failed: %w", err)
-			}
+		err := m.runMigration(migrationCtx, migration, directionDown, func(tx *gorm.DB) error {
+			return m.removeMigrationRecord(migrationCtx, tx, migration.Version)
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("failed to roll back migration %s: %w", migration.Version, err)
+		}
 
-			// Record successful migration
-			if err := m.recordMigration(migrationCtx, tx, migration.Version); err != nil {
-				return err
+		rolledBack = append(rolledBack, migration)
+		log.Printf("Successfully rolled back migration: %s", migration.Version)
+	}
+
+	if len(rolledBack) == 0 {
+		log.Printf("No applied migrations to roll back")
+	}
+
+	return rolledBack, nil
+}
+
+// Redo rolls back the most recently applied migration and applies that same
+// migration again, leaving any pending ones alone
+func (m *MigrationManager) Redo(ctx context.Context) error {
+	return m.withLock(ctx, func() error {
+		rolledBack, err := m.down(ctx, 1)
+		if err != nil || len(rolledBack) == 0 {
+			return err
+		}
+
+		migrationCtx, cancel := context.WithTimeout(ctx, migrationTimeout)
+		defer cancel()
+
+		return m.apply(migrationCtx, rolledBack[0])
+	})
+}
+
+// Status reports every known migration along with when it was applied
+func (m *MigrationManager) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := m.LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	appliedVersions, err := m.getAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version}
+		if record, ok := appliedVersions[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+			status.Modified = record.Checksum != "" && record.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Surface records whose files have been removed
+	for version, record := range appliedVersions {
+		if !containsVersion(migrations, version) {
+			statuses = append(statuses, MigrationStatus{Version: version, Applied: true, AppliedAt: record.AppliedAt})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// PendingMigrations returns the versions that have not been applied yet
+func (m *MigrationManager) PendingMigrations(ctx context.Context) ([]string, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Version)
+		}
+	}
+
+	return pending, nil
+}
+
+// Force records the given version and everything before it as applied, and
+// everything after it as not applied, without executing any SQL. It is meant
+// for repairing the migrations table after a manual intervention.
+func (m *MigrationManager) Force(ctx context.Context, version string) error {
+	return m.withLock(ctx, func() error {
+		return m.force(ctx, version)
+	})
+}
+
+func (m *MigrationManager) force(ctx context.Context, version string) error {
+	if err := m.ensureMigrationsTable(ctx); err != nil {
+		return err
+	}
+
+	migrations, err := m.LoadMigrations()
+	if err != nil {
+		return err
+	}
+
+	target := ""
+	for _, migration := range migrations {
+		if migration.Version == version || versionNumber(migration.Version) == version {
+			target = migration.Version
+			break
+		}
+	}
+	if target == "" {
+		return fmt.Errorf("migration %s not found", version)
+	}
+
+	appliedVersions, err := m.getAppliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, migration := range migrations {
+			_, applied := appliedVersions[migration.Version]
+			shouldBeApplied := migration.Version <= target
+
+			switch {
+			case shouldBeApplied && !applied:
+				if err := m.recordMigration(ctx, tx, migration); err != nil {
+					return err
+				}
+				log.Printf("Marked migration as applied: %s", migration.Version)
+			case !shouldBeApplied && applied:
+				if err := m.removeMigrationRecord(ctx, tx, migration.Version); err != nil {
+					return err
+				}
+				log.Printf("Marked migration as pending: %s", migration.Version)
 			}
+		}
 
-			return nil
-		})
+		return nil
+	})
+}
 
+// CreateMigration writes an empty up/down migration pair into dir, numbered
+// after the latest migration found there
+func CreateMigration(dir, name string) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	if !regexp.MustCompile(`^[a-z0-9_]+$`).MatchString(name) {
+		return "", "", fmt.Errorf("migration name may only contain letters, digits and underscores")
+	}
+
+	manager := &MigrationManager{files: os.DirFS(dir)}
+	migrations, err := manager.LoadMigrations()
+	if err != nil {
+		return "", "", err
+	}
+
+	next := 1
+	if len(migrations) > 0 {
+		last, err := strconv.Atoi(versionNumber(migrations[len(migrations)-1].Version))
 		if err != nil {
-			return fmt.Errorf("failed to apply migration %s: %w", migration.Version, err)
+			return "", "", fmt.Errorf("failed to parse latest migration version: %w", err)
 		}
+		next = last + 1
+	}
+
+	version := fmt.Sprintf("%04d_%s", next, name)
+	upPath := filepath.Join(dir, version+".up.sql")
+	downPath := filepath.Join(dir, version+".down.sql")
 
-		log.Printf("Successfully applied migration: %s", migration.Version)
+	if err := os.WriteFile(upPath, []byte("-- "+version+" up\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", upPath, err)
+	}
+	if err := os.WriteFile(downPath, []byte("-- "+version+" down\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", downPath, err)
 	}
 
-	log.Printf("All migrations completed successfully")
-	return nil
+	return upPath, downPath, nil
 }
 
 // Migrate is a convenience function that uses the default migration manager
@@ -317,20 +645,16 @@ func Migrate(ctx context.Context, db *gorm.DB) error {
 	return manager.Migrate(ctx)
 }
 
-// AllMigrations holds all migrations in chronological order
-var AllMigrations = []Migration{
-	{
-		Version: "0001_initial_database_setup",
-		Up: func(ctx context.Context, db *gorm.DB) error {
-			manager := NewMigrationManager(db)
-			return manager.applyMigration(ctx, "0001_initial_database_setup", db)
-		},
-	},
-	{
-		Version: "0002_seed_data",
-		Up: func(ctx context.Context, db *gorm.DB) error {
-			manager := NewMigrationManager(db)
-			return manager.applyMigration(ctx, "0002_seed_data", db)
-		},
-	},
+func versionNumber(version string) string {
+	number, _, _ := strings.Cut(version, "_")
+	return number
+}
+
+func containsVersion(migrations []Migration, version string) bool {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
 }
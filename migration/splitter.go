@@ -0,0 +1,238 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Statement is a single SQL statement cut out of a migration file. Offset is
+// the byte offset of its first character in the file and Line the 1-based
+// line it starts on, so database errors can be reported against the file.
+type Statement struct {
+	SQL    string
+	Offset int
+	Line   int
+}
+
+// SplitStatements splits a PostgreSQL script into statements on top-level
+// semicolons. It understands line and nested block comments, standard and
+// E'...' string literals, quoted identifiers and dollar-quoted bodies, so
+// functions, triggers and DO blocks are kept intact. Leading comments are
+// dropped and statements that contain nothing but comments are skipped.
+func SplitStatements(content string) ([]Statement, error) {
+	var statements []Statement
+
+	stmtStart := -1
+	i := 0
+	for i < len(content) {
+		c := content[i]
+
+		switch {
+		case c == '-' && strings.HasPrefix(content[i:], "--"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end + 1
+			}
+			continue
+
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			end, err := skipBlockComment(content, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			continue
+
+		case c == ';':
+			if stmtStart >= 0 {
+				statements = append(statements, newStatement(content, stmtStart, i))
+				stmtStart = -1
+			}
+			i++
+			continue
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+			continue
+		}
+
+		if stmtStart < 0 {
+			stmtStart = i
+		}
+
+		switch {
+		case c == '\'':
+			end, err := skipString(content, i, isEscapeStringPrefix(content, i))
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '"':
+			end, err := skipQuotedIdentifier(content, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '$' && !precededByIdentifier(content, i):
+			tag, ok := dollarTag(content, i)
+			if !ok {
+				i++
+				continue
+			}
+
+			closing := strings.Index(content[i+len(tag):], tag)
+			if closing < 0 {
+				return nil, fmt.Errorf("line %d: unterminated dollar-quoted string %s", lineAt(content, i), tag)
+			}
+			i += len(tag) + closing + len(tag)
+
+		default:
+			i++
+		}
+	}
+
+	if stmtStart >= 0 {
+		statements = append(statements, newStatement(content, stmtStart, len(content)))
+	}
+
+	return statements, nil
+}
+
+func newStatement(content string, start, end int) Statement {
+	return Statement{
+		SQL:    strings.TrimRight(content[start:end], " \t\r\n\f"),
+		Offset: start,
+		Line:   lineAt(content, start),
+	}
+}
+
+// skipBlockComment returns the offset just past the comment starting at i.
+// PostgreSQL block comments nest.
+func skipBlockComment(content string, i int) (int, error) {
+	start := i
+	depth := 0
+	for i < len(content) {
+		switch {
+		case strings.HasPrefix(content[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(content[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i, nil
+			}
+		default:
+			i++
+		}
+	}
+
+	return 0, fmt.Errorf("line %d: unterminated block comment", lineAt(content, start))
+}
+
+// skipString returns the offset just past the string literal whose opening
+// quote is at i. Doubled quotes are always an escaped quote; backslash
+// escapes only apply to E'...' strings.
+func skipString(content string, i int, backslashEscapes bool) (int, error) {
+	start := i
+	i++
+	for i < len(content) {
+		switch content[i] {
+		case '\\':
+			if backslashEscapes {
+				i += 2
+				continue
+			}
+		case '\'':
+			if i+1 < len(content) && content[i+1] == '\'' {
+				i += 2
+				continue
+			}
+			return i + 1, nil
+		}
+		i++
+	}
+
+	return 0, fmt.Errorf("line %d: unterminated string literal", lineAt(content, start))
+}
+
+func skipQuotedIdentifier(content string, i int) (int, error) {
+	start := i
+	i++
+	for i < len(content) {
+		if content[i] == '"' {
+			if i+1 < len(content) && content[i+1] == '"' {
+				i += 2
+				continue
+			}
+			return i + 1, nil
+		}
+		i++
+	}
+
+	return 0, fmt.Errorf("line %d: unterminated quoted identifier", lineAt(content, start))
+}
+
+// dollarTag reads a $tag$ or $$ delimiter starting at i. Positional
+// parameters such as $1 are not tags because a tag cannot start with a digit.
+func dollarTag(content string, i int) (string, bool) {
+	j := i + 1
+	for j < len(content) {
+		c := content[j]
+		if c == '$' {
+			return content[i : j+1], true
+		}
+		if !isIdentifierByte(c) || (j == i+1 && c >= '0' && c <= '9') {
+			return "", false
+		}
+		j++
+	}
+
+	return "", false
+}
+
+// isEscapeStringPrefix reports whether the quote at i opens an E'...' string
+func isEscapeStringPrefix(content string, i int) bool {
+	if i == 0 || (content[i-1] != 'E' && content[i-1] != 'e') {
+		return false
+	}
+	return !precededByIdentifier(content, i-1)
+}
+
+func precededByIdentifier(content string, i int) bool {
+	return i > 0 && (isIdentifierByte(content[i-1]) || content[i-1] == '$')
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') ||
+		c >= utf8.RuneSelf
+}
+
+// lineAt returns the 1-based line number of the byte offset
+func lineAt(content string, offset int) int {
+	return strings.Count(content[:offset], "\n") + 1
+}
+
+// positionInFile converts a 1-based character position inside a statement,
+// as reported by PostgreSQL, into a line and column in the migration file
+func positionInFile(content string, stmt Statement, position int) (int, int) {
+	offset := stmt.Offset
+	for n := 1; n < position && offset < len(content); n++ {
+		_, size := utf8.DecodeRuneInString(content[offset:])
+		offset += size
+	}
+
+	line := lineAt(content, offset)
+	lineStart := strings.LastIndexByte(content[:offset], '\n') + 1
+	column := utf8.RuneCountInString(content[lineStart:offset]) + 1
+
+	return line, column
+}
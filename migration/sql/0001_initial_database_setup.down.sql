@@ -0,0 +1,13 @@
+-- Drop tables in reverse dependency order
+DROP TABLE IF EXISTS user_sessions;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS delivery_types;
+DROP TABLE IF EXISTS item_types;
+DROP TABLE IF EXISTS zones;
+DROP TABLE IF EXISTS cities;
+DROP TABLE IF EXISTS stores;
+DROP TABLE IF EXISTS users;
+
+-- Drop ENUM types
+DROP TYPE IF EXISTS order_status_enum;
+DROP TYPE IF EXISTS order_type_enum;
@@ -0,0 +1,37 @@
+-- Remove sample orders
+DELETE FROM orders WHERE consignment_id IN (
+    'CON202501001', 'CON202501002', 'CON202501003',
+    'CON202501004', 'CON202501005', 'CON202501006'
+);
+
+-- Remove seeded stores
+DELETE FROM stores WHERE name IN (
+    'Tech World Dhaka', 'Fashion Plaza', 'Grocery Mart', 'Book Corner',
+    'Electronics Hub', 'Clothing Store', 'Food Court', 'Medical Store'
+);
+
+-- Remove seeded delivery types
+DELETE FROM delivery_types WHERE name IN (
+    'Standard Delivery', 'Express Delivery', 'Same Day Delivery',
+    'Next Day Delivery', 'Urgent Delivery'
+);
+
+-- Remove seeded item types
+DELETE FROM item_types WHERE name IN (
+    'Electronics', 'Clothing', 'Food & Beverage', 'Documents', 'Cosmetics',
+    'Books', 'Home Appliances', 'Jewelry', 'Toys', 'Medicine'
+);
+
+-- Remove seeded zones and cities
+DELETE FROM zones WHERE city_id IN (
+    SELECT id FROM cities WHERE name IN ('Dhaka', 'Chittagong', 'Sylhet')
+) AND name IN (
+    'Dhanmondi', 'Gulshan', 'Uttara', 'Mirpur', 'Wari', 'Motijheel', 'Tejgaon', 'Banani',
+    'Agrabad', 'Nasirabad', 'Panchlaish', 'Khulshi',
+    'Zindabazar', 'Amberkhana', 'Subhanighat'
+);
+
+DELETE FROM cities WHERE name IN (
+    'Dhaka', 'Chittagong', 'Sylhet', 'Rajshahi',
+    'Khulna', 'Barishal', 'Rangpur', 'Mymensingh'
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS uq_delivery_types_name;
+DROP INDEX IF EXISTS uq_item_types_name;
+DROP INDEX IF EXISTS uq_zones_city_id_name;
+DROP INDEX IF EXISTS uq_cities_name;
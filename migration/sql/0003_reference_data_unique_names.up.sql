@@ -0,0 +1,6 @@
+-- Reference rows are identified by name so seeding can upsert them. Deleted
+-- rows are left out so a name can be reused after a soft delete.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cities_name ON cities (name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_zones_city_id_name ON zones (city_id, name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_item_types_name ON item_types (name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_delivery_types_name ON delivery_types (name) WHERE deleted_at IS NULL;
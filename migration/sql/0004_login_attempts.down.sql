@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS lockout_count;
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
+
+DROP TABLE IF EXISTS login_attempts;
@@ -0,0 +1,20 @@
+-- Every login attempt is recorded so failures can be counted per email and
+-- per IP, and so suspicious activity can be reviewed
+CREATE TABLE IF NOT EXISTS login_attempts (
+                               id BIGSERIAL PRIMARY KEY,
+                               email VARCHAR(255) NOT NULL,
+                               user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+                               ip_address VARCHAR(45) NOT NULL,
+                               user_agent VARCHAR(512),
+                               succeeded BOOLEAN NOT NULL,
+                               failure_reason VARCHAR(50),
+                               created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email_created_at ON login_attempts(email, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip_address_created_at ON login_attempts(ip_address, created_at);
+
+-- An account is locked until locked_until; lockout_count doubles the next
+-- lockout and is reset by a successful login
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL DEFAULT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS lockout_count INTEGER NOT NULL DEFAULT 0;
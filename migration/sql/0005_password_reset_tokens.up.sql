@@ -0,0 +1,12 @@
+-- Only a SHA-256 hash of each reset token is stored, so the table cannot be
+-- used to reset passwords
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+                               id BIGSERIAL PRIMARY KEY,
+                               user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               token_hash VARCHAR(64) NOT NULL UNIQUE,
+                               expires_at TIMESTAMP NOT NULL,
+                               used_at TIMESTAMP NULL DEFAULT NULL,
+                               created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
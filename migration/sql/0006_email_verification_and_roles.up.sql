@@ -0,0 +1,19 @@
+-- Accounts that existed before signup verification keep working
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP NULL DEFAULT NULL;
+UPDATE users SET email_verified_at = COALESCE(created_at, CURRENT_TIMESTAMP) WHERE email_verified_at IS NULL;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'merchant';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_role;
+ALTER TABLE users ADD CONSTRAINT chk_users_role CHECK (role IN ('merchant', 'admin'));
+
+-- Only a SHA-256 hash of each verification token is stored
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+                               id BIGSERIAL PRIMARY KEY,
+                               user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               token_hash VARCHAR(64) NOT NULL UNIQUE,
+                               expires_at TIMESTAMP NOT NULL,
+                               used_at TIMESTAMP NULL DEFAULT NULL,
+                               created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
@@ -0,0 +1,24 @@
+-- TOTP secrets are encrypted by the app. A row with no enabled_at is an
+-- enrollment waiting for its first code. last_used_step stops a code from
+-- being used twice.
+CREATE TABLE IF NOT EXISTS user_mfa (
+                               user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                               secret_ciphertext TEXT NOT NULL,
+                               enabled_at TIMESTAMP NULL DEFAULT NULL,
+                               last_used_step BIGINT NOT NULL DEFAULT 0,
+                               created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                               updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only a SHA-256 hash of each recovery code is stored
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+                               id BIGSERIAL PRIMARY KEY,
+                               user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               code_hash VARCHAR(64) NOT NULL,
+                               used_at TIMESTAMP NULL DEFAULT NULL,
+                               created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                               UNIQUE (user_id, code_hash)
+);
+
+-- Admin routes can require a session that passed the second factor
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS mfa_verified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- An empty language means the user has not chosen one, so the
+-- Accept-Language header of each request decides
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(5) NOT NULL DEFAULT '';
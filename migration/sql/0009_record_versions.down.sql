@@ -0,0 +1,4 @@
+ALTER TABLE stores DROP COLUMN IF EXISTS version;
+ALTER TABLE zones DROP COLUMN IF EXISTS version;
+ALTER TABLE cities DROP COLUMN IF EXISTS version;
+ALTER TABLE orders DROP COLUMN IF EXISTS version;
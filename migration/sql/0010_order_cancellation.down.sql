@@ -0,0 +1,5 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancellation_fee;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancellation_note;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancellation_reason;
+DROP TABLE IF EXISTS cancellation_reasons;
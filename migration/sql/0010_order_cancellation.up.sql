@@ -0,0 +1,19 @@
+-- Reasons a merchant may give for cancelling an order, managed by admins
+CREATE TABLE IF NOT EXISTS cancellation_reasons (
+                                 id BIGSERIAL PRIMARY KEY,
+                                 code VARCHAR(50) NOT NULL,
+                                 description VARCHAR(255) NOT NULL,
+                                 note_required BOOLEAN NOT NULL DEFAULT FALSE,
+                                 created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                 updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                 deleted_at TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cancellation_reasons_code ON cancellation_reasons (code) WHERE deleted_at IS NULL;
+
+-- Orders keep the reason code rather than a reference, so removing a reason
+-- from the list leaves past cancellations as they were
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancellation_reason VARCHAR(50) NULL DEFAULT NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancellation_note TEXT NULL DEFAULT NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancellation_fee DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP NULL DEFAULT NULL;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS idx_cancellation_reasons_deleted_at;
+DROP INDEX IF EXISTS idx_delivery_types_deleted_at;
+DROP INDEX IF EXISTS idx_item_types_deleted_at;
+DROP INDEX IF EXISTS idx_cities_deleted_at;
+DROP INDEX IF EXISTS idx_zones_deleted_at;
+DROP INDEX IF EXISTS idx_stores_deleted_at;
+DROP INDEX IF EXISTS idx_orders_deleted_at;
+
+-- Fails while a deleted user and a live one share an email; purge or rename
+-- the deleted one first
+DROP INDEX IF EXISTS uq_users_email;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
@@ -0,0 +1,13 @@
+-- Deleted rows stay in their tables until the retention job purges them, so
+-- an email is only unique among users who are not deleted
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_users_email ON users (email) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_stores_deleted_at ON stores (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_zones_deleted_at ON zones (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_cities_deleted_at ON cities (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_item_types_deleted_at ON item_types (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_delivery_types_deleted_at ON delivery_types (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_cancellation_reasons_deleted_at ON cancellation_reasons (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
@@ -0,0 +1,2 @@
+-- The removed sample data is not put back; load it with `oms seed demo`
+SELECT 1;
@@ -0,0 +1,13 @@
+-- Data is loaded by the seed package (`oms seed`) now. The sample orders and
+-- stores 0002 inserted belong to the demo seed set, so they are removed here;
+-- the cities, zones, item types and delivery types stay as reference data.
+-- Stores that real orders use are kept.
+DELETE FROM orders WHERE consignment_id IN (
+    'CON202501001', 'CON202501002', 'CON202501003',
+    'CON202501004', 'CON202501005', 'CON202501006'
+);
+
+DELETE FROM stores WHERE name IN (
+    'Tech World Dhaka', 'Fashion Plaza', 'Grocery Mart', 'Book Corner',
+    'Electronics Hub', 'Clothing Store', 'Food Court', 'Medical Store'
+) AND NOT EXISTS (SELECT 1 FROM orders WHERE orders.store_id = stores.id);
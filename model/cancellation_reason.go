@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CancellationReason is one of the reasons an order can be cancelled for.
+// Orders record its Code.
+type CancellationReason struct {
+	ID           int64          `json:"id" gorm:"primaryKey;autoIncrement"`
+	Code         string         `json:"code" gorm:"type:varchar(50);not null"`
+	Description  string         `json:"description" gorm:"type:varchar(255);not null"`
+	NoteRequired bool           `json:"note_required" gorm:"not null;default:false"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt    time.Time      `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
@@ -1,12 +1,18 @@
 package model
 
-import "time"
+import (
+	"oms/money"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type City struct {
-	ID              int64      `json:"id" gorm:"primaryKey:autoIncrement"`
-	Name            string     `json:"name" gorm:"type:varchar(100);not null"`
-	BaseDeliveryFee float64    `json:"base_delivery_fee" gorm:"type:decimal(10,2);default:100.00"`
-	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt       *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID              int64          `json:"id" gorm:"primaryKey:autoIncrement"`
+	Name            string         `json:"name" gorm:"type:varchar(100);not null"`
+	BaseDeliveryFee money.Amount   `json:"base_delivery_fee" gorm:"type:decimal(10,2);default:100.00"`
+	Version         int64          `json:"version" gorm:"not null;default:1"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
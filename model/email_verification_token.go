@@ -0,0 +1,14 @@
+package model
+
+import (
+	"time"
+)
+
+type EmailVerificationToken struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int64      `json:"user_id" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);unique;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+type LoginAttempt struct {
+	ID            int64     `json:"id" gorm:"primaryKey"`
+	Email         string    `json:"email" gorm:"type:varchar(255);not null"`
+	UserID        *int64    `json:"user_id,omitempty"`
+	IPAddress     string    `json:"ip_address" gorm:"type:varchar(45);not null"`
+	UserAgent     string    `json:"user_agent" gorm:"type:varchar(512)"`
+	Succeeded     bool      `json:"succeeded" gorm:"not null"`
+	FailureReason string    `json:"failure_reason,omitempty" gorm:"type:varchar(50)"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
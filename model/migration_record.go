@@ -4,5 +4,6 @@ import "time"
 
 type MigrationRecord struct {
 	Version   string    `gorm:"primaryKey;size:255"`
+	Checksum  string    `gorm:"size:64"`
 	AppliedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
 }
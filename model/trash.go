@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// TrashedRecord is a soft deleted row of any table, named by Label, such as
+// a city's name or an order's consignment ID
+type TrashedRecord struct {
+	ID        int64
+	Label     string
+	DeletedAt time.Time
+}
@@ -2,13 +2,20 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID           int64      `json:"id" gorm:"primaryKey"`
-	Email        string     `json:"email" gorm:"type:varchar(255);unique;not null"`
-	PasswordHash string     `json:"-" gorm:"type:varchar(255);not null"` // Hidden from JSON
-	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID              int64          `json:"id" gorm:"primaryKey"`
+	Email           string         `json:"email" gorm:"type:varchar(255);not null"`
+	PasswordHash    string         `json:"-" gorm:"type:varchar(255);not null"` // Hidden from JSON
+	Role            string         `json:"role" gorm:"type:varchar(20);not null;default:merchant"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at,omitempty"`
+	LockedUntil     *time.Time     `json:"-"`
+	LockoutCount    int            `json:"-" gorm:"not null;default:0"`
+	Language        string         `json:"language,omitempty" gorm:"type:varchar(5);not null;default:''"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
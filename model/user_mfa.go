@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+type UserMFA struct {
+	UserID           int64      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	SecretCiphertext string     `json:"-" gorm:"type:text;not null"`
+	EnabledAt        *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep     int64      `json:"-" gorm:"not null;default:0"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName keeps GORM from pluralising the table to user_mfas
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+type MFARecoveryCode struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int64      `json:"user_id" gorm:"not null"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -10,6 +10,8 @@ type UserSession struct {
 	AccessToken  string     `json:"access_token" gorm:"type:varchar(255);not null"`
 	RefreshToken string     `json:"refresh_token" gorm:"type:varchar(255);not null"`
 	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null"`
+	MFAVerified  bool       `json:"mfa_verified" gorm:"not null;default:false"`
+	Language     string     `json:"language,omitempty" gorm:"->"` // The user's preference, read along with the session
 	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt    *time.Time `json:"deleted_at,omitempty" gorm:"index"`
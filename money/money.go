@@ -0,0 +1,166 @@
+// Package money represents amounts of Bangladeshi taka exactly, as a whole
+// number of paisa, so sums and fees never drift the way float64 does.
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount is a sum of money in paisa, the hundredth part of a taka. It is
+// written to JSON as a number of taka with two decimals, such as 60.50, and
+// stored in decimal(10,2) columns.
+type Amount int64
+
+const (
+	// Paisa is the smallest amount
+	Paisa Amount = 1
+	// Taka is one taka, a hundred paisa
+	Taka Amount = 100
+)
+
+// FromTaka converts a number of taka to an Amount, rounding half away from
+// zero to the nearest paisa. Use it only for values that are not exact
+// already, such as a fee per kg times a weight.
+func FromTaka(taka float64) Amount {
+	return Amount(math.Round(taka * float64(Taka)))
+}
+
+// Parse reads a decimal number of taka such as "60", "60.5" or "-3.25"
+// exactly. More than two decimals is an error rather than being rounded.
+func Parse(value string) (Amount, error) {
+	text := strings.TrimSpace(value)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+
+	whole, fraction, hasFraction := strings.Cut(text, ".")
+	if whole == "" || (hasFraction && fraction == "") || len(fraction) > 2 || !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("invalid amount %s: use taka with at most two decimal places", value)
+	}
+
+	taka, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || taka > math.MaxInt64/int64(Taka)-1 {
+		return 0, fmt.Errorf("amount %s is too large", value)
+	}
+
+	paisa, _ := strconv.ParseInt((fraction + "00")[:2], 10, 64)
+	amount := Amount(taka)*Taka + Amount(paisa)
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Taka returns the amount as a number of taka, for display and reports only
+func (a Amount) Taka() float64 {
+	return float64(a) / float64(Taka)
+}
+
+// Percent returns percent per cent of the amount, rounded up to the next
+// paisa so a fee is never short by a fraction
+func (a Amount) Percent(percent int64) Amount {
+	return Amount(ceilDiv(int64(a)*percent, 100))
+}
+
+// RoundUp rounds the amount up to a whole multiple of unit, such as Taka
+func (a Amount) RoundUp(unit Amount) Amount {
+	return Amount(ceilDiv(int64(a), int64(unit))) * unit
+}
+
+// ceilDiv divides rounding towards positive infinity; divisor is positive
+func ceilDiv(dividend, divisor int64) int64 {
+	quotient := dividend / divisor
+	if dividend%divisor > 0 {
+		quotient++
+	}
+	return quotient
+}
+
+// String formats the amount in taka with two decimals, such as 60.50
+func (a Amount) String() string {
+	sign := ""
+	paisa := int64(a)
+	if paisa < 0 {
+		sign = "-"
+		paisa = -paisa
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, paisa/int64(Taka), paisa%int64(Taka))
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number of taka. A number with more than two
+// decimals is rejected rather than rounded.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+
+	amount, err := Parse(text)
+	if err != nil {
+		return err
+	}
+
+	*a = amount
+	return nil
+}
+
+// Value stores the amount as a decimal string, which PostgreSQL reads into
+// numeric columns exactly
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads a numeric column, which the driver returns as text
+func (a *Amount) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case string:
+		return a.scanText(value)
+	case []byte:
+		return a.scanText(string(value))
+	case int64:
+		*a = Amount(value) * Taka
+		return nil
+	case float64:
+		*a = FromTaka(value)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into money.Amount", src)
+	}
+}
+
+func (a *Amount) scanText(text string) error {
+	// numeric columns with a scale of two never have more decimals, but
+	// an unconstrained numeric may have trailing zeros
+	if whole, fraction, ok := strings.Cut(text, "."); ok && len(fraction) > 2 {
+		text = whole + "." + strings.TrimRight(fraction, "0")
+		text = strings.TrimSuffix(text, ".")
+	}
+
+	amount, err := Parse(text)
+	if err != nil {
+		return err
+	}
+
+	*a = amount
+	return nil
+}
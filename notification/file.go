@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type fileNotifier struct {
+	dir string
+}
+
+// NewFileNotifier writes each message to its own file in dir, so messages
+// such as password reset links can be read during local development
+func NewFileNotifier(dir string) Notifier {
+	return fileNotifier{dir: dir}
+}
+
+func (n fileNotifier) Notify(_ context.Context, message Message) error {
+	if err := os.MkdirAll(n.dir, 0o750); err != nil {
+		return fmt.Errorf("error creating notification directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.txt", time.Now().UTC().Format("20060102T150405.000000000"), safeFileName(message.To))
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", message.To, message.Subject, message.Body)
+
+	if err := os.WriteFile(filepath.Join(n.dir, name), []byte(content), 0o640); err != nil {
+		return fmt.Errorf("error writing notification: %w", err)
+	}
+
+	return nil
+}
+
+func safeFileName(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		default:
+			return '_'
+		}
+	}, value)
+}
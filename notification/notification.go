@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+const (
+	// NotifierLog writes messages to the application log
+	NotifierLog = "log"
+	// NotifierFile writes each message to a file
+	NotifierFile = "file"
+)
+
+// Message is a notice for one recipient, addressed by email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to users. Implementations decide the channel,
+// such as email or a message queue.
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// NewNotifier returns the notifier named by kind
+func NewNotifier(kind, dir string) (Notifier, error) {
+	switch kind {
+	case NotifierLog, "":
+		return NewLogNotifier(), nil
+	case NotifierFile:
+		return NewFileNotifier(dir), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", kind)
+	}
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier writes messages to the log instead of delivering them,
+// which is enough for development and until a delivery channel is set up
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+func (logNotifier) Notify(_ context.Context, message Message) error {
+	log.Printf("Notification to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
@@ -0,0 +1,52 @@
+// Package pricing holds the rules an order's fees are calculated by. The
+// order service and the seeders both use it, so seeded orders are priced the
+// way real ones are.
+package pricing
+
+import (
+	"math"
+	"oms/model"
+	"oms/money"
+)
+
+const (
+	// DefaultBaseDeliveryFee is charged when the recipient city cannot be
+	// looked up
+	DefaultBaseDeliveryFee = 60 * money.Taka
+	// IncludedWeight is the weight in kg the base delivery fee covers
+	IncludedWeight = 1.0
+	// ExtraWeightFee is charged per kg above IncludedWeight, pro rata
+	ExtraWeightFee = 10 * money.Taka
+	// CODFeePercent of the order amount is charged for collecting it
+	CODFeePercent = 1
+)
+
+// DeliveryFee is the base fee of the recipient city plus ExtraWeightFee for
+// every kg above IncludedWeight. Part of a kg is charged pro rata, rounded up
+// to the next paisa.
+func DeliveryFee(baseFee money.Amount, weight float64) money.Amount {
+	if weight <= IncludedWeight {
+		return baseFee
+	}
+
+	// Weights have at most two decimals, so count in hundredths of a kg to
+	// keep the surcharge exact; Percent(1) divides the hundredths back out
+	extraWeight := int64(math.Round((weight - IncludedWeight) * 100))
+	return baseFee + (ExtraWeightFee * money.Amount(extraWeight)).Percent(1)
+}
+
+// CODFee is CODFeePercent of the order amount, rounded up to the next whole
+// taka
+func CODFee(orderAmount money.Amount) money.Amount {
+	return orderAmount.Percent(CODFeePercent).RoundUp(money.Taka)
+}
+
+// Apply sets the fees and the amount to collect of order from its order
+// amount, weight and discounts. The result depends on nothing else, so
+// applying it again after an edit never compounds earlier fees.
+func Apply(order *model.Order, baseDeliveryFee money.Amount) {
+	order.DeliveryFee = DeliveryFee(baseDeliveryFee, order.ItemWeight)
+	order.CodFee = CODFee(order.OrderAmount)
+	order.TotalFee = order.DeliveryFee + order.CodFee - order.PromoDiscount - order.Discount
+	order.AmountToCollect = order.OrderAmount + order.TotalFee
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// fallbackRetryAfter is how long the fallback is used after the primary
+// fails before the primary is tried again
+const fallbackRetryAfter = 5 * time.Second
+
+type fallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+
+	mu         sync.Mutex
+	retryAt    time.Time
+	falledBack bool
+}
+
+// NewFallbackLimiter uses primary and switches to fallback while primary
+// returns errors, so an outage of the shared store does not leave the API
+// unprotected or take it down
+func NewFallbackLimiter(primary, fallback Limiter) Limiter {
+	return &fallbackLimiter{primary: primary, fallback: fallback}
+}
+
+func (l *fallbackLimiter) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+	if !l.usingFallback() {
+		result, err := l.primary.Allow(ctx, key, policy)
+		if err == nil {
+			l.recovered()
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return Result{}, err
+		}
+		l.failed(err)
+	}
+
+	return l.fallback.Allow(ctx, key, policy)
+}
+
+func (l *fallbackLimiter) usingFallback() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return time.Now().Before(l.retryAt)
+}
+
+func (l *fallbackLimiter) failed(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.retryAt = time.Now().Add(fallbackRetryAfter)
+	if !l.falledBack {
+		l.falledBack = true
+		log.Printf("Rate limiter store unavailable, limiting in memory: %v", err)
+	}
+}
+
+func (l *fallbackLimiter) recovered() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.falledBack {
+		l.falledBack = false
+		log.Printf("Rate limiter store available again")
+	}
+}
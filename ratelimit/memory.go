@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many calls pass between removals of idle counters
+const sweepEvery = 1000
+
+type memoryLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*window
+	calls   int
+}
+
+// window holds the times of the counted requests of one subject, oldest first
+type window struct {
+	hits   []time.Time
+	length time.Duration
+}
+
+// NewMemoryLimiter keeps the counters in process memory. Each app instance
+// counts on its own, so the effective limit is multiplied by the number of
+// instances.
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{windows: make(map[string]*window)}
+}
+
+func (l *memoryLimiter) Allow(_ context.Context, key string, policy Policy) (Result, error) {
+	now := time.Now()
+	key = policy.Name + ":" + key
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	w, ok := l.windows[key]
+	if !ok {
+		w = &window{length: policy.Window}
+		l.windows[key] = w
+	}
+
+	hits := dropBefore(w.hits, now.Add(-policy.Window))
+	allowed := len(hits) < policy.Limit
+	if allowed {
+		hits = append(hits, now)
+	}
+	w.hits = hits
+
+	resetAfter := policy.Window
+	if len(hits) > 0 {
+		resetAfter = hits[0].Add(policy.Window).Sub(now)
+	}
+
+	return newResult(policy, allowed, len(hits), resetAfter), nil
+}
+
+// sweep removes counters with no request left in their window
+func (l *memoryLimiter) sweep(now time.Time) {
+	for key, w := range l.windows {
+		if len(w.hits) == 0 || now.Sub(w.hits[len(w.hits)-1]) > w.length {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// dropBefore removes the hits at or before cutoff; hits are in time order
+func dropBefore(hits []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+
+	return hits[i:]
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy allows Limit requests per subject within any Window long stretch of
+// time. Name keeps the counters of different policies apart.
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Result is the outcome of one request against a policy
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is how long until the oldest counted request leaves the
+	// window and frees a slot
+	ResetAfter time.Duration
+}
+
+// Limiter counts requests in a sliding window
+type Limiter interface {
+	Allow(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// ParsePolicy reads a policy written as LIMIT/WINDOW, e.g. 10/1m
+func ParsePolicy(name, value string) (Policy, error) {
+	limitPart, windowPart, ok := strings.Cut(value, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("must be LIMIT/WINDOW such as 10/1m, got %q", value)
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(limitPart))
+	if err != nil || limit <= 0 {
+		return Policy{}, fmt.Errorf("limit must be a positive number, got %q", limitPart)
+	}
+
+	window, err := time.ParseDuration(strings.TrimSpace(windowPart))
+	if err != nil || window < time.Second {
+		return Policy{}, fmt.Errorf("window must be a duration of at least 1s, got %q", windowPart)
+	}
+
+	return Policy{Name: name, Limit: limit, Window: window}, nil
+}
+
+func (p Policy) String() string {
+	return fmt.Sprintf("%d/%s", p.Limit, p.Window)
+}
+
+func newResult(policy Policy, allowed bool, count int, resetAfter time.Duration) Result {
+	return Result{
+		Allowed:    allowed,
+		Limit:      policy.Limit,
+		Remaining:  max(policy.Limit-count, 0),
+		ResetAfter: max(resetAfter, 0),
+	}
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// slidingWindowScript keeps one sorted set entry per counted request, scored
+// by its time in milliseconds. Entries older than the window are dropped
+// before counting, so the window slides with every request. Returns whether
+// the request is allowed, the count including it, and the milliseconds until
+// the oldest entry expires.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+redis.call('PEXPIRE', key, window)
+return {allowed, count, reset}
+`)
+
+type redisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter keeps the counters in Redis so the limits hold across every
+// app instance
+func NewRedisLimiter(client *redis.Client) Limiter {
+	return &redisLimiter{client: client}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+	now := time.Now()
+	member := strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+
+	reply, err := slidingWindowScript.Run(l.client.WithContext(ctx), []string{"oms:ratelimit:" + policy.Name + ":" + key},
+		now.UnixMilli(), policy.Window.Milliseconds(), policy.Limit, member).Result()
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+
+	allowed, _ := values[0].(int64)
+	count, _ := values[1].(int64)
+	reset, _ := values[2].(int64)
+
+	return newResult(policy, allowed == 1, int(count), time.Duration(reset)*time.Millisecond), nil
+}
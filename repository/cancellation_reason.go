@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"oms/apperror"
+	"oms/domain"
+	"oms/model"
+
+	"gorm.io/gorm"
+)
+
+type cancellationReasonRepository struct {
+	masterDb  *gorm.DB
+	replicaDb *gorm.DB
+}
+
+func NewCancellationReasonRepository(masterDB, replicaDB *gorm.DB) domain.CancellationReasonRepository {
+	return &cancellationReasonRepository{
+		masterDb:  masterDB,
+		replicaDb: replicaDB,
+	}
+}
+
+func (r *cancellationReasonRepository) CreateCancellationReason(ctx context.Context, reason model.CancellationReason) error {
+	err := r.masterDb.WithContext(ctx).Create(&reason).Error
+	return writeError(err, "cancellation reason")
+}
+
+func (r *cancellationReasonRepository) GetCancellationReasonByID(ctx context.Context, id int64) (model.CancellationReason, error) {
+	var reason model.CancellationReason
+	err := r.replicaDb.WithContext(ctx).First(&reason, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.CancellationReason{}, apperror.NotFound("cancellation reason with ID %d not found", id)
+		}
+		return model.CancellationReason{}, err
+	}
+
+	return reason, nil
+}
+
+func (r *cancellationReasonRepository) GetCancellationReasonByCode(ctx context.Context, code string) (model.CancellationReason, error) {
+	var reason model.CancellationReason
+	err := r.replicaDb.WithContext(ctx).Where("code = ?", code).First(&reason).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.CancellationReason{}, apperror.NotFound("cancellation reason '%s' not found", code)
+		}
+		return model.CancellationReason{}, err
+	}
+
+	return reason, nil
+}
+
+func (r *cancellationReasonRepository) GetAllCancellationReasons(ctx context.Context, limit, offset int) ([]model.CancellationReason, error) {
+	var reasons []model.CancellationReason
+	err := r.replicaDb.WithContext(ctx).Order("code ASC").Limit(limit).Offset(offset).Find(&reasons).Error
+	return reasons, err
+}
+
+func (r *cancellationReasonRepository) UpdateCancellationReason(ctx context.Context, reason model.CancellationReason) error {
+	result := r.masterDb.WithContext(ctx).Save(&reason)
+	if result.Error != nil {
+		return writeError(result.Error, "cancellation reason")
+	}
+
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("cancellation reason with ID %d not found", reason.ID)
+	}
+
+	return nil
+}
+
+func (r *cancellationReasonRepository) DeleteCancellationReason(ctx context.Context, id int64) error {
+	return softDelete(ctx, r.masterDb, &model.CancellationReason{}, id, "cancellation-reasons", apperror.NotFound("cancellation reason with ID %d not found", id))
+}
@@ -1,8 +1,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 
@@ -21,16 +22,17 @@ func NewCityRepository(masterDB, replicaDB *gorm.DB) domain.CityRepository {
 	}
 }
 
-func (r *cityRepository) CreateCity(city model.City) error {
-	return r.masterDb.Create(&city).Error
+func (r *cityRepository) CreateCity(ctx context.Context, city model.City) error {
+	err := r.masterDb.WithContext(ctx).Create(&city).Error
+	return writeError(err, "city")
 }
 
-func (r *cityRepository) GetCityByID(id int64) (model.City, error) {
+func (r *cityRepository) GetCityByID(ctx context.Context, id int64) (model.City, error) {
 	var city model.City
-	err := r.replicaDb.First(&city, id).Error
+	err := r.replicaDb.WithContext(ctx).First(&city, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.City{}, fmt.Errorf("city with ID %d not found", id)
+			return model.City{}, apperror.NotFound("city with ID %d not found", id)
 		}
 		return model.City{}, err
 	}
@@ -38,39 +40,36 @@ func (r *cityRepository) GetCityByID(id int64) (model.City, error) {
 	return city, nil
 }
 
-func (r *cityRepository) GetAllCities(limit, offset int) ([]model.City, error) {
+func (r *cityRepository) GetAllCities(ctx context.Context, limit, offset int) ([]model.City, error) {
 	var cities []model.City
-	err := r.replicaDb.Limit(limit).Offset(offset).Find(&cities).Error
+	err := r.replicaDb.WithContext(ctx).Limit(limit).Offset(offset).Find(&cities).Error
 	return cities, err
 }
 
-func (r *cityRepository) UpdateCity(city model.City) error {
-	result := r.masterDb.Save(&city)
-	if result.Error != nil {
-		return result.Error
-	}
+// UpdateCity saves city if the row is still at city.Version, and returns the
+// version it moved to
+func (r *cityRepository) UpdateCity(ctx context.Context, city model.City) (int64, error) {
+	version := city.Version
+	city.Version++
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("city with ID %d not found", city.ID)
+	err := updateVersioned(ctx, r.masterDb, &city, city.ID, version, "city", apperror.NotFound("city with ID %d not found", city.ID))
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return city.Version, nil
 }
 
-func (r *cityRepository) DeleteCity(id int64) error {
-	result := r.masterDb.Delete(&model.City{}, id)
-	if result.Error != nil {
-		return result.Error
-	}
-	return nil
+func (r *cityRepository) DeleteCity(ctx context.Context, id int64) error {
+	return softDelete(ctx, r.masterDb, &model.City{}, id, "cities", apperror.NotFound("city with ID %d not found", id))
 }
 
-func (r *cityRepository) GetCityByName(name string) (model.City, error) {
+func (r *cityRepository) GetCityByName(ctx context.Context, name string) (model.City, error) {
 	var city model.City
-	err := r.replicaDb.Where("name = ?", name).First(&city).Error
+	err := r.replicaDb.WithContext(ctx).Where("name = ?", name).First(&city).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.City{}, fmt.Errorf("city with name '%s' not found", name)
+			return model.City{}, apperror.NotFound("city with name '%s' not found", name)
 		}
 		return model.City{}, err
 	}
@@ -1,8 +1,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 
@@ -21,16 +22,17 @@ func NewDeliveryTypeRepository(masterDB, replicaDB *gorm.DB) domain.DeliveryType
 	}
 }
 
-func (r *deliveryTypeRepository) CreateDeliveryType(deliveryType model.DeliveryType) error {
-	return r.masterDb.Create(&deliveryType).Error
+func (r *deliveryTypeRepository) CreateDeliveryType(ctx context.Context, deliveryType model.DeliveryType) error {
+	err := r.masterDb.WithContext(ctx).Create(&deliveryType).Error
+	return writeError(err, "delivery type")
 }
 
-func (r *deliveryTypeRepository) GetDeliveryTypeByID(id int64) (model.DeliveryType, error) {
+func (r *deliveryTypeRepository) GetDeliveryTypeByID(ctx context.Context, id int64) (model.DeliveryType, error) {
 	var deliveryType model.DeliveryType
-	err := r.replicaDb.First(&deliveryType, id).Error
+	err := r.replicaDb.WithContext(ctx).First(&deliveryType, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.DeliveryType{}, fmt.Errorf("delivery type with ID %d not found", id)
+			return model.DeliveryType{}, apperror.NotFound("delivery type with ID %d not found", id)
 		}
 		return model.DeliveryType{}, err
 	}
@@ -38,44 +40,35 @@ func (r *deliveryTypeRepository) GetDeliveryTypeByID(id int64) (model.DeliveryTy
 	return deliveryType, nil
 }
 
-func (r *deliveryTypeRepository) GetAllDeliveryTypes(limit, offset int) ([]model.DeliveryType, error) {
+func (r *deliveryTypeRepository) GetAllDeliveryTypes(ctx context.Context, limit, offset int) ([]model.DeliveryType, error) {
 	var deliveryTypes []model.DeliveryType
-	err := r.replicaDb.Order("name ASC").Limit(limit).Offset(offset).Find(&deliveryTypes).Error
+	err := r.replicaDb.WithContext(ctx).Order("name ASC").Limit(limit).Offset(offset).Find(&deliveryTypes).Error
 	return deliveryTypes, err
 }
 
-func (r *deliveryTypeRepository) UpdateDeliveryType(deliveryType model.DeliveryType) error {
-	result := r.masterDb.Save(&deliveryType)
+func (r *deliveryTypeRepository) UpdateDeliveryType(ctx context.Context, deliveryType model.DeliveryType) error {
+	result := r.masterDb.WithContext(ctx).Save(&deliveryType)
 	if result.Error != nil {
-		return result.Error
+		return writeError(result.Error, "delivery type")
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("delivery type with ID %d not found", deliveryType.ID)
+		return apperror.NotFound("delivery type with ID %d not found", deliveryType.ID)
 	}
 
 	return nil
 }
 
-func (r *deliveryTypeRepository) DeleteDeliveryType(id int64) error {
-	result := r.masterDb.Delete(&model.DeliveryType{}, id)
-	if result.Error != nil {
-		return result.Error
-	}
-
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("delivery type with ID %d not found", id)
-	}
-
-	return nil
+func (r *deliveryTypeRepository) DeleteDeliveryType(ctx context.Context, id int64) error {
+	return softDelete(ctx, r.masterDb, &model.DeliveryType{}, id, "delivery-types", apperror.NotFound("delivery type with ID %d not found", id))
 }
 
-func (r *deliveryTypeRepository) GetDeliveryTypeByName(name string) (model.DeliveryType, error) {
+func (r *deliveryTypeRepository) GetDeliveryTypeByName(ctx context.Context, name string) (model.DeliveryType, error) {
 	var deliveryType model.DeliveryType
-	err := r.replicaDb.Where("name = ?", name).First(&deliveryType).Error
+	err := r.replicaDb.WithContext(ctx).Where("name = ?", name).First(&deliveryType).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.DeliveryType{}, fmt.Errorf("delivery type with name '%s' not found", name)
+			return model.DeliveryType{}, apperror.NotFound("delivery type with name '%s' not found", name)
 		}
 		return model.DeliveryType{}, err
 	}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"oms/apperror"
+	"oms/i18n"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL codes of the constraint violations reported as typed errors
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// writeError reports a violated constraint on insert or update as a typed
+// error, so losing a race with a concurrent write is answered as a conflict
+// rather than an internal error. Other errors are returned unchanged.
+func writeError(err error, entity i18n.Text) error {
+	switch pgErrorCode(err) {
+	case uniqueViolation:
+		return apperror.Conflict("%s already exists", entity).Wrap(err)
+	case foreignKeyViolation:
+		return apperror.Validation("%s refers to a record that does not exist", entity).Wrap(err)
+	}
+	return err
+}
+
+// deleteError reports a delete blocked by records that still refer to the
+// row as a conflict. Other errors are returned unchanged.
+func deleteError(err error, entity i18n.Text) error {
+	if pgErrorCode(err) == foreignKeyViolation {
+		return apperror.Conflict("%s is still in use", entity).Wrap(err)
+	}
+	return err
+}
+
+func pgErrorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
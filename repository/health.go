@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"oms/connection"
+	"oms/domain"
+	migrations "oms/migration"
+	"time"
+
+	"github.com/go-redis/redis"
+	"gorm.io/gorm"
+)
+
+type healthRepository struct {
+	masterDb   *gorm.DB
+	replicas   []connection.Replica
+	redis      *redis.Client
+	migrations *migrations.MigrationManager
+}
+
+func NewHealthRepository(masterDB *gorm.DB, replicas []connection.Replica, redisClient *redis.Client) domain.HealthRepository {
+	return &healthRepository{
+		masterDb:   masterDB,
+		replicas:   replicas,
+		redis:      redisClient,
+		migrations: migrations.NewMigrationManager(masterDB),
+	}
+}
+
+func (r *healthRepository) PingMaster(ctx context.Context) error {
+	return ping(ctx, r.masterDb)
+}
+
+func (r *healthRepository) PingRedis(ctx context.Context) error {
+	return r.redis.WithContext(ctx).Ping().Err()
+}
+
+func (r *healthRepository) ReplicaAddresses() []string {
+	addresses := make([]string, 0, len(r.replicas))
+	for _, replica := range r.replicas {
+		addresses = append(addresses, replica.Address)
+	}
+	return addresses
+}
+
+// ReplicaLag asks the replica at address itself, bypassing the router, so a
+// replica out of rotation is still checked
+func (r *healthRepository) ReplicaLag(ctx context.Context, address string) (time.Duration, error) {
+	for _, replica := range r.replicas {
+		if replica.Address == address {
+			return connection.ReplicaLag(ctx, replica.DB)
+		}
+	}
+	return 0, fmt.Errorf("unknown replica %s", address)
+}
+
+func (r *healthRepository) PendingMigrations(ctx context.Context) ([]string, error) {
+	return r.migrations.PendingMigrations(ctx)
+}
+
+func ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
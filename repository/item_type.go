@@ -1,8 +1,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 
@@ -21,16 +22,17 @@ func NewItemTypeRepository(masterDB, replicaDB *gorm.DB) domain.ItemTypeReposito
 	}
 }
 
-func (r *itemTypeRepository) CreateItemType(itemType model.ItemType) error {
-	return r.masterDb.Create(&itemType).Error
+func (r *itemTypeRepository) CreateItemType(ctx context.Context, itemType model.ItemType) error {
+	err := r.masterDb.WithContext(ctx).Create(&itemType).Error
+	return writeError(err, "item type")
 }
 
-func (r *itemTypeRepository) GetItemTypeByID(id int64) (model.ItemType, error) {
+func (r *itemTypeRepository) GetItemTypeByID(ctx context.Context, id int64) (model.ItemType, error) {
 	var itemType model.ItemType
-	err := r.replicaDb.First(&itemType, id).Error
+	err := r.replicaDb.WithContext(ctx).First(&itemType, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.ItemType{}, fmt.Errorf("item type with ID %d not found", id)
+			return model.ItemType{}, apperror.NotFound("item type with ID %d not found", id)
 		}
 		return model.ItemType{}, err
 	}
@@ -38,44 +40,35 @@ func (r *itemTypeRepository) GetItemTypeByID(id int64) (model.ItemType, error) {
 	return itemType, nil
 }
 
-func (r *itemTypeRepository) GetAllItemTypes(limit, offset int) ([]model.ItemType, error) {
+func (r *itemTypeRepository) GetAllItemTypes(ctx context.Context, limit, offset int) ([]model.ItemType, error) {
 	var itemTypes []model.ItemType
-	err := r.replicaDb.Order("name ASC").Limit(limit).Offset(offset).Find(&itemTypes).Error
+	err := r.replicaDb.WithContext(ctx).Order("name ASC").Limit(limit).Offset(offset).Find(&itemTypes).Error
 	return itemTypes, err
 }
 
-func (r *itemTypeRepository) UpdateItemType(itemType model.ItemType) error {
-	result := r.masterDb.Save(&itemType)
+func (r *itemTypeRepository) UpdateItemType(ctx context.Context, itemType model.ItemType) error {
+	result := r.masterDb.WithContext(ctx).Save(&itemType)
 	if result.Error != nil {
-		return result.Error
+		return writeError(result.Error, "item type")
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("item type with ID %d not found", itemType.ID)
+		return apperror.NotFound("item type with ID %d not found", itemType.ID)
 	}
 
 	return nil
 }
 
-func (r *itemTypeRepository) DeleteItemType(id int64) error {
-	result := r.masterDb.Delete(&model.ItemType{}, id)
-	if result.Error != nil {
-		return result.Error
-	}
-
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("item type with ID %d not found", id)
-	}
-
-	return nil
+func (r *itemTypeRepository) DeleteItemType(ctx context.Context, id int64) error {
+	return softDelete(ctx, r.masterDb, &model.ItemType{}, id, "item-types", apperror.NotFound("item type with ID %d not found", id))
 }
 
-func (r *itemTypeRepository) GetItemTypeByName(name string) (model.ItemType, error) {
+func (r *itemTypeRepository) GetItemTypeByName(ctx context.Context, name string) (model.ItemType, error) {
 	var itemType model.ItemType
-	err := r.replicaDb.Where("name = ?", name).First(&itemType).Error
+	err := r.replicaDb.WithContext(ctx).Where("name = ?", name).First(&itemType).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.ItemType{}, fmt.Errorf("item type with name '%s' not found", name)
+			return model.ItemType{}, apperror.NotFound("item type with name '%s' not found", name)
 		}
 		return model.ItemType{}, err
 	}
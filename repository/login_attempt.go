@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"oms/consts"
+	"oms/domain"
+	"oms/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// countedFailureReasons are the failures that count towards a lockout: wrong
+// passwords and wrong second factors
+var countedFailureReasons = []string{consts.LoginFailureInvalidCredentials, consts.LoginFailureInvalidMFACode}
+
+type loginAttemptRepository struct {
+	masterDb  *gorm.DB
+	replicaDb *gorm.DB
+}
+
+func NewLoginAttemptRepository(masterDB, replicaDB *gorm.DB) domain.LoginAttemptRepository {
+	return &loginAttemptRepository{
+		masterDb:  masterDB,
+		replicaDb: replicaDB,
+	}
+}
+
+func (r *loginAttemptRepository) CreateLoginAttempt(ctx context.Context, attempt model.LoginAttempt) error {
+	return r.masterDb.WithContext(ctx).Create(&attempt).Error
+}
+
+// CountFailuresByEmail counts wrong credentials and codes for email since
+// the later of since and the last successful login. Failures are counted on
+// the master so a lagging replica cannot hide the latest attempts.
+func (r *loginAttemptRepository) CountFailuresByEmail(ctx context.Context, email string, since time.Time) (int64, error) {
+	var count int64
+	err := r.masterDb.WithContext(ctx).Model(&model.LoginAttempt{}).
+		Where("email = ? AND failure_reason IN ? AND created_at > ?", email, countedFailureReasons, since).
+		Where("created_at > COALESCE((SELECT MAX(created_at) FROM login_attempts WHERE email = ? AND succeeded), '-infinity')", email).
+		Count(&count).Error
+	return count, err
+}
+
+// CountFailuresByIP counts wrong credentials and codes from ipAddress since
+// since, on the master
+func (r *loginAttemptRepository) CountFailuresByIP(ctx context.Context, ipAddress string, since time.Time) (int64, error) {
+	var count int64
+	err := r.masterDb.WithContext(ctx).Model(&model.LoginAttempt{}).
+		Where("ip_address = ? AND failure_reason IN ? AND created_at > ?", ipAddress, countedFailureReasons, since).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"oms/apperror"
+	"oms/domain"
+	"oms/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mfaRepository reads from the master too: a code must be checked against
+// the latest enrollment and last used step, never a lagging copy
+type mfaRepository struct {
+	masterDb  *gorm.DB
+	replicaDb *gorm.DB
+}
+
+func NewMFARepository(masterDB, replicaDB *gorm.DB) domain.MFARepository {
+	return &mfaRepository{
+		masterDb:  masterDB,
+		replicaDb: replicaDB,
+	}
+}
+
+func (r *mfaRepository) GetUserMFA(ctx context.Context, userID int64) (model.UserMFA, error) {
+	var mfa model.UserMFA
+	err := r.masterDb.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.UserMFA{}, apperror.NotFound("mfa not enrolled")
+		}
+		return model.UserMFA{}, err
+	}
+
+	return mfa, nil
+}
+
+// SavePendingMFA stores a new secret waiting for its first code, replacing
+// an earlier enrollment that was never confirmed
+func (r *mfaRepository) SavePendingMFA(ctx context.Context, userID int64, secretCiphertext string) error {
+	mfa := model.UserMFA{UserID: userID, SecretCiphertext: secretCiphertext}
+	result := r.masterDb.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"secret_ciphertext": secretCiphertext,
+			"last_used_step":    0,
+			"updated_at":        time.Now(),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_mfa.enabled_at IS NULL"}}},
+	}).Create(&mfa)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return apperror.Conflict("mfa already enabled")
+	}
+
+	return nil
+}
+
+// EnableMFA confirms the pending enrollment, records the step of the code
+// that confirmed it and replaces the recovery codes
+func (r *mfaRepository) EnableMFA(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error {
+	return r.masterDb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.UserMFA{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": time.Now(), "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperror.Conflict("mfa already enabled")
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]model.MFARecoveryCode, 0, len(recoveryCodeHashes))
+		for _, hash := range recoveryCodeHashes {
+			codes = append(codes, model.MFARecoveryCode{UserID: userID, CodeHash: hash})
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseTOTPStep records step as used. It returns false when the same or a
+// later step was already used, so each code works once.
+func (r *mfaRepository) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	result := r.masterDb.WithContext(ctx).Model(&model.UserMFA{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used, returning false
+// when the user has no such unused code
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	result := r.masterDb.WithContext(ctx).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *mfaRepository) DeleteMFA(ctx context.Context, userID int64) error {
+	return r.masterDb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&model.UserMFA{}).Error
+	})
+}
@@ -1,9 +1,10 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"math"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 	"oms/types"
@@ -23,16 +24,17 @@ func NewOrderRepository(masterDB, replicaDB *gorm.DB) domain.OrderRepository {
 	}
 }
 
-func (r *orderRepository) CreateOrder(order model.Order) error {
-	return r.masterDb.Create(&order).Error
+func (r *orderRepository) CreateOrder(ctx context.Context, order model.Order) error {
+	err := r.masterDb.WithContext(ctx).Create(&order).Error
+	return writeError(err, "order")
 }
 
-func (r *orderRepository) GetOrderByConsignmentID(consignmentID string) (model.Order, error) {
+func (r *orderRepository) GetOrderByConsignmentID(ctx context.Context, consignmentID string) (model.Order, error) {
 	var order model.Order
-	err := r.replicaDb.Where("consignment_id = ?", consignmentID).First(&order).Error
+	err := r.replicaDb.WithContext(ctx).Where("consignment_id = ?", consignmentID).First(&order).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.Order{}, fmt.Errorf("order with consignment ID '%s' not found", consignmentID)
+			return model.Order{}, apperror.NotFound("order with consignment ID '%s' not found", consignmentID)
 		}
 		return model.Order{}, err
 	}
@@ -40,9 +42,9 @@ func (r *orderRepository) GetOrderByConsignmentID(consignmentID string) (model.O
 	return order, nil
 }
 
-func (r *orderRepository) ListAllOrders(listReq types.OrderListRequest) ([]model.Order, model.Pagination, error) {
+func (r *orderRepository) ListAllOrders(ctx context.Context, listReq types.OrderListRequest) ([]model.Order, model.Pagination, error) {
 	var orders []model.Order
-	query := r.replicaDb.Model(&model.Order{}).Where("deleted_at IS NULL")
+	query := r.replicaDb.WithContext(ctx).Model(&model.Order{})
 
 	if listReq.UserId != 0 {
 		query = query.Where("user_id = ?", listReq.UserId)
@@ -88,41 +90,20 @@ func (r *orderRepository) ListAllOrders(listReq types.OrderListRequest) ([]model
 	return orders, pagination, nil
 }
 
-func (r *orderRepository) UpdateOrder(order model.Order) error {
-	result := r.masterDb.Save(&order)
-	if result.Error != nil {
-		return result.Error
-	}
-
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("order with ID %d not found", order.ID)
-	}
-
-	return nil
-}
-
-func (r *orderRepository) UpdateOrderStatus(id int64, status string) error {
-	result := r.masterDb.Model(&model.Order{}).Where("id = ?", id).Update("order_status", status)
-	if result.Error != nil {
-		return result.Error
-	}
+// UpdateOrder saves order if the row is still at order.Version, and returns the
+// version it moved to
+func (r *orderRepository) UpdateOrder(ctx context.Context, order model.Order) (int64, error) {
+	version := order.Version
+	order.Version++
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("order with ID %d not found", id)
+	err := updateVersioned(ctx, r.masterDb, &order, order.ID, version, "order", apperror.NotFound("order with ID %d not found", order.ID))
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return order.Version, nil
 }
 
-func (r *orderRepository) DeleteOrder(id int64) error {
-	result := r.masterDb.Delete(&model.Order{}, id)
-	if result.Error != nil {
-		return result.Error
-	}
-
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("order with ID %d not found", id)
-	}
-
-	return nil
+func (r *orderRepository) DeleteOrder(ctx context.Context, id int64) error {
+	return softDelete(ctx, r.masterDb, &model.Order{}, id, "orders", apperror.NotFound("order with ID %d not found", id))
 }
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"oms/apperror"
+	"oms/domain"
+	"oms/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type passwordResetTokenRepository struct {
+	masterDb  *gorm.DB
+	replicaDb *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(masterDB, replicaDB *gorm.DB) domain.PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{
+		masterDb:  masterDB,
+		replicaDb: replicaDB,
+	}
+}
+
+func (r *passwordResetTokenRepository) CreatePasswordResetToken(ctx context.Context, token model.PasswordResetToken) error {
+	return r.masterDb.WithContext(ctx).Create(&token).Error
+}
+
+// ConsumePasswordResetToken marks an unused, unexpired token as used and
+// returns its user. The check and the update are one statement, so a token
+// can only be used once even by concurrent requests.
+func (r *passwordResetTokenRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (int64, error) {
+	var userIDs []int64
+	now := time.Now()
+	err := r.masterDb.WithContext(ctx).Raw(
+		"UPDATE password_reset_tokens SET used_at = ? WHERE token_hash = ? AND used_at IS NULL AND expires_at > ? RETURNING user_id",
+		now, tokenHash, now,
+	).Scan(&userIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if len(userIDs) == 0 {
+		return 0, apperror.Validation("invalid or expired reset token")
+	}
+
+	return userIDs[0], nil
+}
+
+// InvalidateUserTokens marks every outstanding token of the user as used
+func (r *passwordResetTokenRepository) InvalidateUserTokens(ctx context.Context, userID int64) error {
+	return r.masterDb.WithContext(ctx).Model(&model.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
@@ -1,8 +1,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 
@@ -21,16 +22,17 @@ func NewStoreRepository(masterDB, replicaDB *gorm.DB) domain.StoreRepository {
 	}
 }
 
-func (r *storeRepository) CreateStore(store model.Store) error {
-	return r.masterDb.Create(&store).Error
+func (r *storeRepository) CreateStore(ctx context.Context, store model.Store) error {
+	err := r.masterDb.WithContext(ctx).Create(&store).Error
+	return writeError(err, "store")
 }
 
-func (r *storeRepository) GetStoreByID(id int64) (model.Store, error) {
+func (r *storeRepository) GetStoreByID(ctx context.Context, id int64) (model.Store, error) {
 	var store model.Store
-	err := r.replicaDb.First(&store, id).Error
+	err := r.replicaDb.WithContext(ctx).First(&store, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.Store{}, fmt.Errorf("store with ID %d not found", id)
+			return model.Store{}, apperror.NotFound("store with ID %d not found", id)
 		}
 		return model.Store{}, err
 	}
@@ -38,44 +40,36 @@ func (r *storeRepository) GetStoreByID(id int64) (model.Store, error) {
 	return store, nil
 }
 
-func (r *storeRepository) GetAllStores(limit, offset int) ([]model.Store, error) {
+func (r *storeRepository) GetAllStores(ctx context.Context, limit, offset int) ([]model.Store, error) {
 	var stores []model.Store
-	err := r.replicaDb.Limit(limit).Offset(offset).Find(&stores).Error
+	err := r.replicaDb.WithContext(ctx).Limit(limit).Offset(offset).Find(&stores).Error
 	return stores, err
 }
 
-func (r *storeRepository) UpdateStore(store model.Store) error {
-	result := r.masterDb.Save(&store)
-	if result.Error != nil {
-		return result.Error
-	}
+// UpdateStore saves store if the row is still at store.Version, and returns the
+// version it moved to
+func (r *storeRepository) UpdateStore(ctx context.Context, store model.Store) (int64, error) {
+	version := store.Version
+	store.Version++
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("store with ID %d not found", store.ID)
+	err := updateVersioned(ctx, r.masterDb, &store, store.ID, version, "store", apperror.NotFound("store with ID %d not found", store.ID))
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return store.Version, nil
 }
 
-func (r *storeRepository) DeleteStore(id int64) error {
-	result := r.masterDb.Delete(&model.Store{}, id)
-	if result.Error != nil {
-		return result.Error
-	}
-
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("store with ID %d not found", id)
-	}
-
-	return nil
+func (r *storeRepository) DeleteStore(ctx context.Context, id int64) error {
+	return softDelete(ctx, r.masterDb, &model.Store{}, id, "stores", apperror.NotFound("store with ID %d not found", id))
 }
 
-func (r *storeRepository) GetStoreByName(name string) (model.Store, error) {
+func (r *storeRepository) GetStoreByName(ctx context.Context, name string) (model.Store, error) {
 	var store model.Store
-	err := r.replicaDb.Where("name = ?", name).First(&store).Error
+	err := r.replicaDb.WithContext(ctx).Where("name = ?", name).First(&store).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.Store{}, fmt.Errorf("store with name '%s' not found", name)
+			return model.Store{}, apperror.NotFound("store with name '%s' not found", name)
 		}
 		return model.Store{}, err
 	}
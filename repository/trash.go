@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"oms/apperror"
+	"oms/domain"
+	"oms/i18n"
+	"oms/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// trashKind describes a table whose rows are soft deleted
+type trashKind struct {
+	table  string
+	entity i18n.Text
+	// label is the column that names a row in trash listings
+	label string
+	// referencedBy lists the columns of other tables holding ids of its rows
+	referencedBy []reference
+	// deleteInUse lets rows be deleted while live rows still point at them,
+	// such as a user with orders, which keep their history
+	deleteInUse bool
+}
+
+// reference is a column of table holding ids of another table's rows
+type reference struct {
+	table  string
+	column string
+}
+
+// trashKinds are the records that go to the trash when deleted, keyed by the
+// name admins use for them. Only these table and column names reach SQL.
+var trashKinds = map[string]trashKind{
+	"orders": {table: "orders", entity: "order", label: "consignment_id"},
+	"stores": {table: "stores", entity: "store", label: "name",
+		referencedBy: []reference{{"orders", "store_id"}}},
+	"zones": {table: "zones", entity: "zone", label: "name",
+		referencedBy: []reference{{"orders", "recipient_zone"}}},
+	"cities": {table: "cities", entity: "city", label: "name",
+		referencedBy: []reference{{"zones", "city_id"}, {"orders", "recipient_city"}}},
+	"item-types": {table: "item_types", entity: "item type", label: "name",
+		referencedBy: []reference{{"orders", "item_type"}}},
+	"delivery-types": {table: "delivery_types", entity: "delivery type", label: "name",
+		referencedBy: []reference{{"orders", "delivery_type_id"}}},
+	"cancellation-reasons": {table: "cancellation_reasons", entity: "cancellation reason", label: "code"},
+	"users": {table: "users", entity: "user", label: "email", deleteInUse: true,
+		referencedBy: []reference{{"orders", "user_id"}}},
+}
+
+// purgeOrder lists every kind so that records are purged before the ones
+// they point at
+var purgeOrder = []string{
+	"orders", "stores", "zones", "cities", "item-types", "delivery-types", "cancellation-reasons", "users",
+}
+
+type trashRepository struct {
+	masterDb  *gorm.DB
+	replicaDb *gorm.DB
+}
+
+func NewTrashRepository(masterDB, replicaDB *gorm.DB) domain.TrashRepository {
+	return &trashRepository{
+		masterDb:  masterDB,
+		replicaDb: replicaDB,
+	}
+}
+
+func (r *trashRepository) ListDeleted(ctx context.Context, kind string, limit, offset int) ([]model.TrashedRecord, error) {
+	trash, err := trashKindOf(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []model.TrashedRecord
+	err = r.replicaDb.WithContext(ctx).Table(trash.table).
+		Select("id, " + trash.label + " AS label, deleted_at").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&records).Error
+	return records, err
+}
+
+// Restore takes the row with id out of the trash. Rows it points at must
+// be restored first, so a restored record never refers to a deleted one.
+func (r *trashRepository) Restore(ctx context.Context, kind string, id int64) error {
+	trash, err := trashKindOf(kind)
+	if err != nil {
+		return err
+	}
+
+	query := r.masterDb.WithContext(ctx).Table(trash.table).Where("id = ? AND deleted_at IS NOT NULL", id)
+	for _, parent := range trashKinds {
+		for _, ref := range parent.referencedBy {
+			if ref.table == trash.table {
+				query = query.Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.id = %[2]s.%[3]s AND %[1]s.deleted_at IS NOT NULL)",
+					parent.table, trash.table, ref.column))
+			}
+		}
+	}
+
+	result := query.Update("deleted_at", nil)
+	if result.Error != nil {
+		return writeError(result.Error, trash.entity)
+	}
+
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := r.masterDb.WithContext(ctx).Table(trash.table).Where("id = ? AND deleted_at IS NOT NULL", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return apperror.NotFound("%s with ID %d is not in the trash", trash.entity, id)
+		}
+		return apperror.Conflict("%s refers to a record in the trash, restore that first", trash.entity)
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently deletes rows that went to the trash before
+// deletedBefore, returning how many of each kind were purged. Rows that any
+// other row, deleted or not, still points at are kept until that row goes.
+func (r *trashRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (map[string]int64, error) {
+	purged := make(map[string]int64, len(purgeOrder))
+	for _, kind := range purgeOrder {
+		trash := trashKinds[kind]
+
+		conditions := []string{"deleted_at < ?"}
+		for _, ref := range trash.referencedBy {
+			conditions = append(conditions, fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.%[2]s = %[3]s.id)",
+				ref.table, ref.column, trash.table))
+		}
+
+		result := r.masterDb.WithContext(ctx).Exec(
+			"DELETE FROM "+trash.table+" WHERE "+strings.Join(conditions, " AND "), deletedBefore)
+		if result.Error != nil {
+			return purged, fmt.Errorf("failed to purge %s: %w", kind, result.Error)
+		}
+
+		purged[kind] = result.RowsAffected
+	}
+
+	return purged, nil
+}
+
+func trashKindOf(kind string) (trashKind, error) {
+	trash, ok := trashKinds[kind]
+	if !ok {
+		return trashKind{}, apperror.NotFound("there is no trash for %s", kind)
+	}
+	return trash, nil
+}
+
+// softDelete moves the row with id of record's table to the trash. Unless
+// the kind allows it, a row that live rows still point at is in use and
+// stays; a row that is missing or deleted already is reported with notFound.
+func softDelete(ctx context.Context, db *gorm.DB, record any, id int64, kind string, notFound error) error {
+	trash := trashKinds[kind]
+
+	query := db.WithContext(ctx)
+	if !trash.deleteInUse {
+		for _, ref := range trash.referencedBy {
+			query = query.Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.%[2]s = %[3]s.id AND %[1]s.deleted_at IS NULL)",
+				ref.table, ref.column, trash.table))
+		}
+	}
+
+	result := query.Delete(record, id)
+	if result.Error != nil {
+		return deleteError(result.Error, trash.entity)
+	}
+
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := db.WithContext(ctx).Model(record).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return notFound
+		}
+		return apperror.Conflict("%s is still in use", trash.entity)
+	}
+
+	return nil
+}
@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -21,16 +23,17 @@ func NewUserRepository(masterDB, replicaDB *gorm.DB) domain.UserRepository {
 	}
 }
 
-func (r *userRepository) CreateUser(user model.User) error {
-	return r.masterDb.Create(&user).Error
+func (r *userRepository) CreateUser(ctx context.Context, user model.User) (model.User, error) {
+	err := r.masterDb.WithContext(ctx).Create(&user).Error
+	return user, writeError(err, "user")
 }
 
-func (r *userRepository) GetUserByID(id int64) (model.User, error) {
+func (r *userRepository) GetUserByID(ctx context.Context, id int64) (model.User, error) {
 	var user model.User
-	err := r.replicaDb.First(&user, id).Error
+	err := r.replicaDb.WithContext(ctx).First(&user, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.User{}, fmt.Errorf("user with ID %d not found", id)
+			return model.User{}, apperror.NotFound("user with ID %d not found", id)
 		}
 		return model.User{}, err
 	}
@@ -38,47 +41,113 @@ func (r *userRepository) GetUserByID(id int64) (model.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) GetAllUsers(limit, offset int) ([]model.User, error) {
+func (r *userRepository) GetAllUsers(ctx context.Context, limit, offset int) ([]model.User, error) {
 	var users []model.User
-	err := r.replicaDb.Order("created_at DESC").Limit(limit).Offset(offset).Find(&users).Error
+	err := r.replicaDb.WithContext(ctx).Order("created_at DESC").Limit(limit).Offset(offset).Find(&users).Error
 	return users, err
 }
 
-func (r *userRepository) UpdateUserEmail(user model.User) error {
-	result := r.masterDb.Save(&user)
+func (r *userRepository) UpdateUserEmail(ctx context.Context, user model.User) error {
+	result := r.masterDb.WithContext(ctx).Save(&user)
 	if result.Error != nil {
-		return result.Error
+		return writeError(result.Error, "user")
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user with ID %d not found", user.ID)
+		return apperror.NotFound("user with ID %d not found", user.ID)
 	}
 
 	return nil
 }
 
-func (r *userRepository) DeleteUser(id int64) error {
-	result := r.masterDb.Delete(&model.User{}, id)
+func (r *userRepository) DeleteUser(ctx context.Context, id int64) error {
+	// The user goes to the trash and is signed out everywhere
+	return r.masterDb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := softDelete(ctx, tx, &model.User{}, id, "users", apperror.NotFound("user with ID %d not found", id)); err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", id).Delete(&model.UserSession{}).Error
+	})
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	var user model.User
+	err := r.replicaDb.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.User{}, apperror.NotFound("user with email '%s' not found", email)
+		}
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+// LockUser locks the account until until and counts the lockout. It returns
+// false without changing anything when the account is already locked, so
+// concurrent failures lock it once.
+func (r *userRepository) LockUser(ctx context.Context, id int64, until time.Time) (bool, error) {
+	result := r.masterDb.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until <= ?)", id, time.Now()).
+		Updates(map[string]interface{}{
+			"locked_until":  until,
+			"lockout_count": gorm.Expr("lockout_count + 1"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *userRepository) ResetLockout(ctx context.Context, id int64) error {
+	return r.masterDb.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"locked_until":  nil,
+			"lockout_count": 0,
+		}).Error
+}
+
+// UpdatePassword replaces the password hash and lifts any lockout, since
+// whoever set the password has proven they own the account
+func (r *userRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	result := r.masterDb.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"password_hash": passwordHash,
+			"locked_until":  nil,
+			"lockout_count": 0,
+		})
 	if result.Error != nil {
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user with ID %d not found", id)
+		return apperror.NotFound("user with ID %d not found", id)
 	}
 
 	return nil
 }
 
-func (r *userRepository) GetUserByEmail(email string) (model.User, error) {
-	var user model.User
-	err := r.replicaDb.Where("email = ?", email).First(&user).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.User{}, fmt.Errorf("user with email '%s' not found", email)
-		}
-		return model.User{}, err
+// UpdateLanguage sets the language the user is written to in. An empty
+// language clears the preference.
+func (r *userRepository) UpdateLanguage(ctx context.Context, id int64, language string) error {
+	result := r.masterDb.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("language", language)
+	if result.Error != nil {
+		return result.Error
 	}
 
-	return user, nil
+	if result.RowsAffected == 0 {
+		return apperror.NotFound("user with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// MarkEmailVerified records that the user proved they own their email. An
+// already verified email keeps its original time.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+	return r.masterDb.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND email_verified_at IS NULL", id).
+		Update("email_verified_at", time.Now()).Error
 }
@@ -1,8 +1,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 	"time"
@@ -22,16 +23,20 @@ func NewUserSessionRepository(masterDB, replicaDB *gorm.DB) domain.UserSessionRe
 	}
 }
 
-func (r *userSessionRepository) CreateUserSession(session model.UserSession) error {
-	return r.masterDb.Create(&session).Error
+func (r *userSessionRepository) CreateUserSession(ctx context.Context, session model.UserSession) error {
+	return r.masterDb.WithContext(ctx).Create(&session).Error
 }
 
-func (r *userSessionRepository) GetUserSessionByAccessToken(accessToken string) (model.UserSession, error) {
+func (r *userSessionRepository) GetUserSessionByAccessToken(ctx context.Context, accessToken string) (model.UserSession, error) {
 	var session model.UserSession
-	err := r.replicaDb.Where("access_token = ?", accessToken).First(&session).Error
+	err := r.replicaDb.WithContext(ctx).
+		Select("user_sessions.*, users.language").
+		Joins("JOIN users ON users.id = user_sessions.user_id AND users.deleted_at IS NULL").
+		Where("user_sessions.access_token = ?", accessToken).
+		First(&session).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.UserSession{}, fmt.Errorf("user session not found")
+			return model.UserSession{}, apperror.Unauthorized("session not found")
 		}
 		return model.UserSession{}, err
 	}
@@ -39,20 +44,26 @@ func (r *userSessionRepository) GetUserSessionByAccessToken(accessToken string)
 	return session, nil
 }
 
-func (r *userSessionRepository) DeleteExpiredSessions() error {
-	result := r.masterDb.Where("expires_at <= ?", time.Now()).Delete(&model.UserSession{})
+func (r *userSessionRepository) DeleteExpiredSessions(ctx context.Context) error {
+	result := r.masterDb.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&model.UserSession{})
 	return result.Error
 }
 
-func (r *userSessionRepository) InvalidateSession(accessToken string) error {
-	result := r.masterDb.Where("access_token = ?", accessToken).Delete(&model.UserSession{})
+func (r *userSessionRepository) InvalidateSession(ctx context.Context, accessToken string) error {
+	result := r.masterDb.WithContext(ctx).Where("access_token = ?", accessToken).Delete(&model.UserSession{})
 	if result.Error != nil {
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("session not found")
+		return apperror.Unauthorized("session not found")
 	}
 
 	return nil
 }
+
+// InvalidateUserSessions ends every session of the user, such as after a
+// password change
+func (r *userSessionRepository) InvalidateUserSessions(ctx context.Context, userID int64) error {
+	return r.masterDb.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.UserSession{}).Error
+}
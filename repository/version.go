@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"oms/apperror"
+	"oms/i18n"
+
+	"gorm.io/gorm"
+)
+
+// updateVersioned writes every column of record, a pointer to a model with a
+// Version field, only while the row with id is still at version, the one the
+// change was based on. record must carry version+1, which the row moves to.
+// A row that has moved on is a conflict, so concurrent edits cannot
+// overwrite each other; a row that is gone is reported with notFound.
+func updateVersioned(ctx context.Context, db *gorm.DB, record any, id, version int64, entity i18n.Text, notFound error) error {
+	result := db.WithContext(ctx).Model(record).
+		Where("id = ? AND version = ?", id, version).
+		Select("*").Omit("id", "created_at").
+		Updates(record)
+	if result.Error != nil {
+		return writeError(result.Error, entity)
+	}
+
+	if result.RowsAffected == 0 {
+		return versionError(ctx, db, record, id, entity, notFound)
+	}
+
+	return nil
+}
+
+// versionError explains why a conditional update of the row with id matched
+// nothing
+func versionError(ctx context.Context, db *gorm.DB, record any, id int64, entity i18n.Text, notFound error) error {
+	var count int64
+	if err := db.WithContext(ctx).Model(record).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return notFound
+	}
+
+	return apperror.Conflict("%s was changed by someone else, fetch it again and retry", entity)
+}
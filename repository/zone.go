@@ -1,8 +1,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 
@@ -21,16 +22,17 @@ func NewZoneRepository(masterDB, replicaDB *gorm.DB) domain.ZoneRepository {
 	}
 }
 
-func (r *zoneRepository) CreateZone(zone model.Zone) error {
-	return r.masterDb.Create(&zone).Error
+func (r *zoneRepository) CreateZone(ctx context.Context, zone model.Zone) error {
+	err := r.masterDb.WithContext(ctx).Create(&zone).Error
+	return writeError(err, "zone")
 }
 
-func (r *zoneRepository) GetZoneByID(id int64) (model.Zone, error) {
+func (r *zoneRepository) GetZoneByID(ctx context.Context, id int64) (model.Zone, error) {
 	var zone model.Zone
-	err := r.replicaDb.First(&zone, id).Error
+	err := r.replicaDb.WithContext(ctx).First(&zone, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.Zone{}, fmt.Errorf("zone with ID %d not found", id)
+			return model.Zone{}, apperror.NotFound("zone with ID %d not found", id)
 		}
 		return model.Zone{}, err
 	}
@@ -38,44 +40,36 @@ func (r *zoneRepository) GetZoneByID(id int64) (model.Zone, error) {
 	return zone, nil
 }
 
-func (r *zoneRepository) GetAllZones(limit, offset int) ([]model.Zone, error) {
+func (r *zoneRepository) GetAllZones(ctx context.Context, limit, offset int) ([]model.Zone, error) {
 	var zones []model.Zone
-	err := r.replicaDb.Limit(limit).Offset(offset).Find(&zones).Error
+	err := r.replicaDb.WithContext(ctx).Limit(limit).Offset(offset).Find(&zones).Error
 	return zones, err
 }
 
-func (r *zoneRepository) UpdateZone(zone model.Zone) error {
-	result := r.masterDb.Save(&zone)
-	if result.Error != nil {
-		return result.Error
-	}
+// UpdateZone saves zone if the row is still at zone.Version, and returns the
+// version it moved to
+func (r *zoneRepository) UpdateZone(ctx context.Context, zone model.Zone) (int64, error) {
+	version := zone.Version
+	zone.Version++
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("zone with ID %d not found", zone.ID)
+	err := updateVersioned(ctx, r.masterDb, &zone, zone.ID, version, "zone", apperror.NotFound("zone with ID %d not found", zone.ID))
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return zone.Version, nil
 }
 
-func (r *zoneRepository) DeleteZone(id int64) error {
-	result := r.masterDb.Delete(&model.Zone{}, id)
-	if result.Error != nil {
-		return result.Error
-	}
-
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("zone with ID %d not found", id)
-	}
-
-	return nil
+func (r *zoneRepository) DeleteZone(ctx context.Context, id int64) error {
+	return softDelete(ctx, r.masterDb, &model.Zone{}, id, "zones", apperror.NotFound("zone with ID %d not found", id))
 }
 
-func (r *zoneRepository) GetZoneByName(name string) (model.Zone, error) {
+func (r *zoneRepository) GetZoneByName(ctx context.Context, name string) (model.Zone, error) {
 	var zone model.Zone
-	err := r.replicaDb.Where("name = ?", name).First(&zone).Error
+	err := r.replicaDb.WithContext(ctx).Where("name = ?", name).First(&zone).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.Zone{}, fmt.Errorf("zone with name '%s' not found", name)
+			return model.Zone{}, apperror.NotFound("zone with name '%s' not found", name)
 		}
 		return model.Zone{}, err
 	}
@@ -83,19 +77,19 @@ func (r *zoneRepository) GetZoneByName(name string) (model.Zone, error) {
 	return zone, nil
 }
 
-func (r *zoneRepository) GetZonesByCityID(cityID int64, limit, offset int) ([]model.Zone, error) {
+func (r *zoneRepository) GetZonesByCityID(ctx context.Context, cityID int64, limit, offset int) ([]model.Zone, error) {
 	var zones []model.Zone
-	err := r.replicaDb.Where("city_id = ?", cityID).
+	err := r.replicaDb.WithContext(ctx).Where("city_id = ?", cityID).
 		Limit(limit).Offset(offset).Find(&zones).Error
 	return zones, err
 }
 
-func (r *zoneRepository) GetZoneByNameAndCityID(name string, cityID int64) (model.Zone, error) {
+func (r *zoneRepository) GetZoneByNameAndCityID(ctx context.Context, name string, cityID int64) (model.Zone, error) {
 	var zone model.Zone
-	err := r.replicaDb.Where("name = ? AND city_id = ?", name, cityID).First(&zone).Error
+	err := r.replicaDb.WithContext(ctx).Where("name = ? AND city_id = ?", name, cityID).First(&zone).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return model.Zone{}, fmt.Errorf("zone with name '%s' in city %d not found", name, cityID)
+			return model.Zone{}, apperror.NotFound("zone with name '%s' in city %d not found", name, cityID)
 		}
 		return model.Zone{}, err
 	}
@@ -103,8 +97,8 @@ func (r *zoneRepository) GetZoneByNameAndCityID(name string, cityID int64) (mode
 	return zone, nil
 }
 
-func (r *zoneRepository) CountZonesByCity(cityID int64) (int64, error) {
+func (r *zoneRepository) CountZonesByCity(ctx context.Context, cityID int64) (int64, error) {
 	var count int64
-	err := r.replicaDb.Model(&model.Zone{}).Where("city_id = ?", cityID).Count(&count).Error
+	err := r.replicaDb.WithContext(ctx).Model(&model.Zone{}).Where("city_id = ?", cityID).Count(&count).Error
 	return count, err
 }
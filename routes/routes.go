@@ -2,18 +2,30 @@ package routes
 
 import (
 	"oms/config"
-	"oms/connection"
+	"oms/consts"
+	"oms/domain"
 	"oms/handler"
 	"oms/middleware"
+	"oms/notification"
+	"oms/ratelimit"
 	"oms/repository"
 	"oms/service"
+	"oms/validation"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-redis/redis"
+	"gorm.io/gorm"
 )
 
-func InitRoutes(e *gin.Engine) {
-	masterDB, replicaDB := connection.InitDB(config.Conf)
-	//redis := connection.GetRedis(config.Conf)
+func InitRoutes(e *gin.Engine, cfg config.Config, masterDB, replicaDB *gorm.DB, redisClient *redis.Client, healthService domain.HealthService, trashService domain.TrashService) {
+
+	// Every bound request is checked against its validate tags
+	binding.Validator = validation.NewGinValidator()
+
+	// The notifier kind was checked when the configuration was validated
+	notifier, _ := notification.NewNotifier(cfg.Notifier, cfg.NotifierFileDir)
 
 	cityRepository := repository.NewCityRepository(masterDB, replicaDB)
 	storeRepository := repository.NewStoreRepository(masterDB, replicaDB)
@@ -22,7 +34,12 @@ func InitRoutes(e *gin.Engine) {
 	deliveryTypeRepository := repository.NewDeliveryTypeRepository(masterDB, replicaDB)
 	userRepository := repository.NewUserRepository(masterDB, replicaDB)
 	userSessionRepository := repository.NewUserSessionRepository(masterDB, replicaDB)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(masterDB, replicaDB)
+	passwordResetTokenRepository := repository.NewPasswordResetTokenRepository(masterDB, replicaDB)
+	emailVerificationTokenRepository := repository.NewEmailVerificationTokenRepository(masterDB, replicaDB)
+	mfaRepository := repository.NewMFARepository(masterDB, replicaDB)
 	orderRepository := repository.NewOrderRepository(masterDB, replicaDB)
+	cancellationReasonRepository := repository.NewCancellationReasonRepository(masterDB, replicaDB)
 
 	cityService := service.NewCityService(cityRepository)
 	storeService := service.NewStoreService(storeRepository)
@@ -30,9 +47,11 @@ func InitRoutes(e *gin.Engine) {
 	itemTypeService := service.NewItemTypeService(itemTypeRepository)
 	deliveryTypeService := service.NewDeliveryTypeService(deliveryTypeRepository)
 	userService := service.NewUserService(userRepository)
-	userSessionService := service.NewUserSessionService(userSessionRepository, config.Conf)
-	authService := service.NewAuthService(userRepository, userSessionService)
-	orderService := service.NewOrderService(orderRepository, storeService, cityService)
+	userSessionService := service.NewUserSessionService(userSessionRepository, cfg)
+	mfaService := service.NewMFAService(mfaRepository, userRepository, cfg)
+	authService := service.NewAuthService(userRepository, loginAttemptRepository, passwordResetTokenRepository, emailVerificationTokenRepository, userSessionService, mfaService, notifier, cfg)
+	orderService := service.NewOrderService(orderRepository, storeService, cityService, zoneService, cancellationReasonRepository, cfg)
+	cancellationReasonService := service.NewCancellationReasonService(cancellationReasonRepository)
 
 	cityHandler := handler.NewCityHandler(cityService)
 	storeHandler := handler.NewStoreHandler(storeService)
@@ -41,15 +60,25 @@ func InitRoutes(e *gin.Engine) {
 	deliveryTypeHandler := handler.NewDeliveryTypeHandler(deliveryTypeService)
 	userHandler := handler.NewUserHandler(userService)
 	authHandler := handler.NewAuthHandler(authService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
 	orderHandler := handler.NewOrderHandler(orderService)
+	cancellationReasonHandler := handler.NewCancellationReasonHandler(cancellationReasonService)
+	healthHandler := handler.NewHealthHandler(healthService)
+	trashHandler := handler.NewTrashHandler(trashService)
 
-	omsRoutes := e.Group("/api/v1")
+	e.GET("/livez", healthHandler.Livez)
+	e.GET("/readyz", healthHandler.Readyz)
 
-	omsRoutes.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// Limits are counted per user on authenticated routes, so the limiter
+	// runs after Auth
+	rateLimit := newRateLimit(cfg, redisClient)
 
-	cityRoutes := omsRoutes.Group("/cities").Use(middleware.Auth(userSessionService))
+	omsRoutes := e.Group("/api/v1")
+	omsRoutes.Use(middleware.Errors(), middleware.Timeout(cfg.RequestTimeout, map[string]time.Duration{
+		"/api/v1/orders/all": cfg.ListRequestTimeout,
+	}))
+
+	cityRoutes := omsRoutes.Group("/cities").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
 	{
 		cityRoutes.POST("", cityHandler.CreateCity)
 		cityRoutes.GET("", cityHandler.GetAllCities)
@@ -59,7 +88,7 @@ func InitRoutes(e *gin.Engine) {
 		cityRoutes.GET("/name/:name", cityHandler.GetCityByName)
 	}
 
-	storeRoutes := omsRoutes.Group("/stores").Use(middleware.Auth(userSessionService))
+	storeRoutes := omsRoutes.Group("/stores").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
 	{
 		storeRoutes.POST("", storeHandler.CreateStore)
 		storeRoutes.GET("", storeHandler.GetAllStores)
@@ -68,7 +97,7 @@ func InitRoutes(e *gin.Engine) {
 		storeRoutes.DELETE("/:id", storeHandler.DeleteStore)
 	}
 
-	zoneRoutes := omsRoutes.Group("/zones").Use(middleware.Auth(userSessionService))
+	zoneRoutes := omsRoutes.Group("/zones").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
 	{
 		zoneRoutes.POST("", zoneHandler.CreateZone)
 		zoneRoutes.GET("", zoneHandler.GetAllZones)
@@ -77,7 +106,7 @@ func InitRoutes(e *gin.Engine) {
 		zoneRoutes.DELETE("/:id", zoneHandler.DeleteZone)
 	}
 
-	itemTypeRoutes := omsRoutes.Group("/item-types").Use(middleware.Auth(userSessionService))
+	itemTypeRoutes := omsRoutes.Group("/item-types").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
 	{
 		itemTypeRoutes.POST("", itemTypeHandler.CreateItemType)
 		itemTypeRoutes.GET("", itemTypeHandler.GetAllItemTypes)
@@ -86,7 +115,7 @@ func InitRoutes(e *gin.Engine) {
 		itemTypeRoutes.DELETE("/:id", itemTypeHandler.DeleteItemType)
 	}
 
-	deliveryTypeRoutes := omsRoutes.Group("/delivery-types").Use(middleware.Auth(userSessionService))
+	deliveryTypeRoutes := omsRoutes.Group("/delivery-types").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
 	{
 		deliveryTypeRoutes.POST("", deliveryTypeHandler.CreateDeliveryType)
 		deliveryTypeRoutes.GET("", deliveryTypeHandler.GetAllDeliveryTypes)
@@ -95,7 +124,13 @@ func InitRoutes(e *gin.Engine) {
 		deliveryTypeRoutes.DELETE("/:id", deliveryTypeHandler.DeleteDeliveryType)
 	}
 
-	userRoutes := omsRoutes.Group("/users")
+	// Admins must have logged in with a second factor when the policy is on
+	requireAdminMFA := func(ctx *gin.Context) { ctx.Next() }
+	if cfg.MFARequiredForAdmin {
+		requireAdminMFA = middleware.RequireMFA()
+	}
+
+	userRoutes := omsRoutes.Group("/users").Use(middleware.Auth(userSessionService, cfg.JWTSecret), middleware.RequireRole(userService, consts.RoleAdmin), requireAdminMFA, rateLimit)
 	{
 		userRoutes.POST("", userHandler.CreateUser)
 		userRoutes.GET("", userHandler.GetAllUsers)
@@ -105,23 +140,92 @@ func InitRoutes(e *gin.Engine) {
 		userRoutes.DELETE("/:id", userHandler.DeleteUser)
 	}
 
-	orderRoutes := omsRoutes.Group("/orders").Use(middleware.Auth(userSessionService))
+	meRoutes := omsRoutes.Group("/users/me").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
+	{
+		meRoutes.PUT("/password", authHandler.ChangePassword)
+		meRoutes.PUT("/language", userHandler.UpdateLanguage)
+	}
+
+	orderRoutes := omsRoutes.Group("/orders").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
 	{
 		orderRoutes.POST("", orderHandler.CreateOrder)
 		orderRoutes.GET("/:consignment_id", orderHandler.GetOrderByConsignmentID)
 		orderRoutes.GET("/all", orderHandler.ListAllOrders)
 		orderRoutes.PUT("", orderHandler.UpdateOrder)
-		orderRoutes.DELETE("/:id", orderHandler.DeleteOrder)
+		orderRoutes.PATCH("/:consignment_id", orderHandler.PatchOrder)
+		orderRoutes.DELETE("/:consignment_id", orderHandler.DeleteOrder)
 		orderRoutes.POST("/:consignment_id/cancel", orderHandler.CancelOrder)
 	}
 
-	loginRoutes := omsRoutes.Group("/auth")
+	cancellationReasonRoutes := omsRoutes.Group("/cancellation-reasons").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
+	{
+		cancellationReasonRoutes.GET("", cancellationReasonHandler.GetAllCancellationReasons)
+		cancellationReasonRoutes.GET("/:id", cancellationReasonHandler.GetCancellationReasonByID)
+	}
+
+	// Merchants pick from the list; only admins manage it
+	cancellationReasonAdminRoutes := omsRoutes.Group("/cancellation-reasons").Use(middleware.Auth(userSessionService, cfg.JWTSecret), middleware.RequireRole(userService, consts.RoleAdmin), requireAdminMFA, rateLimit)
 	{
+		cancellationReasonAdminRoutes.POST("", cancellationReasonHandler.CreateCancellationReason)
+		cancellationReasonAdminRoutes.PUT("", cancellationReasonHandler.UpdateCancellationReason)
+		cancellationReasonAdminRoutes.DELETE("/:id", cancellationReasonHandler.DeleteCancellationReason)
+	}
+
+	trashRoutes := omsRoutes.Group("/trash").Use(middleware.Auth(userSessionService, cfg.JWTSecret), middleware.RequireRole(userService, consts.RoleAdmin), requireAdminMFA, rateLimit)
+	{
+		trashRoutes.GET("/:kind", trashHandler.ListTrash)
+		trashRoutes.POST("/:kind/:id/restore", trashHandler.RestoreFromTrash)
+	}
+
+	loginRoutes := omsRoutes.Group("/auth").Use(rateLimit)
+	{
+		loginRoutes.POST("/signup", authHandler.Signup)
+		loginRoutes.POST("/email/verify", authHandler.VerifyEmail)
+		loginRoutes.POST("/email/verify/resend", authHandler.ResendVerification)
 		loginRoutes.POST("/login", authHandler.Login)
+		loginRoutes.POST("/login/mfa", authHandler.LoginMFA)
+		loginRoutes.POST("/password/forgot", authHandler.ForgotPassword)
+		loginRoutes.POST("/password/reset", authHandler.ResetPassword)
 	}
 
-	logoutRoutes := omsRoutes.Group("/auth").Use(middleware.Auth(userSessionService))
+	logoutRoutes := omsRoutes.Group("/auth").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
 	{
 		logoutRoutes.POST("/logout", authHandler.Logout)
 	}
+
+	mfaRoutes := omsRoutes.Group("/auth/mfa").Use(middleware.Auth(userSessionService, cfg.JWTSecret), rateLimit)
+	{
+		mfaRoutes.GET("", mfaHandler.Status)
+		mfaRoutes.POST("/enroll", mfaHandler.Enroll)
+		mfaRoutes.POST("/enroll/confirm", mfaHandler.ConfirmEnrollment)
+		mfaRoutes.DELETE("", mfaHandler.Disable)
+	}
+}
+
+// newRateLimit builds the rate limiting middleware from the configured
+// policies. Counters live in Redis and fall back to memory while Redis is
+// unavailable.
+func newRateLimit(cfg config.Config, redisClient *redis.Client) gin.HandlerFunc {
+	if !cfg.RateLimitEnabled {
+		return func(ctx *gin.Context) { ctx.Next() }
+	}
+
+	// The policies were checked when the configuration was validated
+	defaultPolicy, _ := ratelimit.ParsePolicy("default", cfg.RateLimitDefault)
+	loginPolicy, _ := ratelimit.ParsePolicy("login", cfg.RateLimitLogin)
+	orderCreatePolicy, _ := ratelimit.ParsePolicy("order_create", cfg.RateLimitOrderCreate)
+
+	limiter := ratelimit.NewFallbackLimiter(ratelimit.NewRedisLimiter(redisClient), ratelimit.NewMemoryLimiter())
+
+	return middleware.RateLimit(limiter, defaultPolicy, map[string]ratelimit.Policy{
+		"POST /api/v1/auth/signup":              loginPolicy,
+		"POST /api/v1/auth/email/verify/resend": loginPolicy,
+		"POST /api/v1/auth/login":               loginPolicy,
+		"POST /api/v1/auth/login/mfa":           loginPolicy,
+		"POST /api/v1/auth/mfa/enroll/confirm":  loginPolicy,
+		"DELETE /api/v1/auth/mfa":               loginPolicy,
+		"POST /api/v1/auth/password/forgot":     loginPolicy,
+		"POST /api/v1/auth/password/reset":      loginPolicy,
+		"POST /api/v1/orders":                   orderCreatePolicy,
+	})
 }
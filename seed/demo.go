@@ -0,0 +1,273 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"oms/consts"
+	"oms/model"
+	"oms/money"
+	"oms/pricing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DemoUserEmail and DemoUserPassword are the credentials of the demo user
+	DemoUserEmail    = "demo@oms.local"
+	DemoUserPassword = "demo1234"
+
+	// DemoAdminEmail and DemoAdminPassword are the credentials of the demo
+	// admin, who can manage users
+	DemoAdminEmail    = "admin@oms.local"
+	DemoAdminPassword = "admin1234"
+)
+
+var demoStores = []model.Store{
+	{Name: "Tech World Dhaka", ContactPhone: "01712345678", Address: "123 Dhanmondi R/A, Dhaka-1205"},
+	{Name: "Fashion Plaza", ContactPhone: "01812345679", Address: "456 Gulshan Avenue, Dhaka-1212"},
+	{Name: "Grocery Mart", ContactPhone: "01912345680", Address: "789 Uttara Sector 7, Dhaka-1230"},
+	{Name: "Book Corner", ContactPhone: "01612345681", Address: "321 New Market, Dhaka-1205"},
+	{Name: "Electronics Hub", ContactPhone: "01512345682", Address: "654 Mirpur 10, Dhaka-1216"},
+	{Name: "Clothing Store", ContactPhone: "01412345683", Address: "987 Wari, Dhaka-1203"},
+	{Name: "Food Court", ContactPhone: "01312345684", Address: "147 Motijheel C/A, Dhaka-1000"},
+	{Name: "Medical Store", ContactPhone: "01212345685", Address: "258 Tejgaon I/A, Dhaka-1208"},
+}
+
+// demoOrder refers to its related rows by name so it does not depend on ids
+type demoOrder struct {
+	order        model.Order
+	store        string
+	city         string
+	zone         string
+	deliveryType string
+	itemType     string
+}
+
+var demoOrders = []demoOrder{
+	{
+		order: model.Order{
+			ConsignmentID: "CON202501001", MerchantOrderID: "MERCHANT001",
+			RecipientName: "John Doe", RecipientPhone: "01711111111", RecipientAddress: "House 10, Road 15, Block C",
+			RecipientArea: "Near Dhanmondi Lake", OrderType: "delivery",
+			ItemQuantity: 1, ItemWeight: 0.5, ItemDescription: "iPhone 15 Pro Max", SpecialInstruction: "Handle with care",
+			OrderAmount: 120000 * money.Taka, OrderStatus: "pending",
+		},
+		store: "Tech World Dhaka", city: "Dhaka", zone: "Dhanmondi", deliveryType: "Standard Delivery", itemType: "Electronics",
+	},
+	{
+		order: model.Order{
+			ConsignmentID: "CON202501002", MerchantOrderID: "MERCHANT002",
+			RecipientName: "Jane Smith", RecipientPhone: "01722222222", RecipientAddress: "Apt 5B, Road 11, Gulshan 2",
+			RecipientArea: "Opposite Gulshan Park", OrderType: "delivery",
+			ItemQuantity: 3, ItemWeight: 1.2, ItemDescription: "Cotton T-Shirts", SpecialInstruction: "Size: Medium, Color: Blue",
+			OrderAmount: 1500 * money.Taka, OrderStatus: "confirmed",
+		},
+		store: "Fashion Plaza", city: "Dhaka", zone: "Gulshan", deliveryType: "Express Delivery", itemType: "Clothing",
+	},
+	{
+		order: model.Order{
+			ConsignmentID: "CON202501003", MerchantOrderID: "MERCHANT003",
+			RecipientName: "Mike Johnson", RecipientPhone: "01733333333", RecipientAddress: "House 25, Sector 11, Uttara",
+			RecipientArea: "Near Uttara University", OrderType: "delivery",
+			ItemQuantity: 2, ItemWeight: 2, ItemDescription: "Fresh Vegetables and Fruits", SpecialInstruction: "Deliver before 6 PM",
+			OrderAmount: 800 * money.Taka, OrderStatus: "picked_up",
+		},
+		store: "Grocery Mart", city: "Dhaka", zone: "Uttara", deliveryType: "Same Day Delivery", itemType: "Food & Beverage",
+	},
+	{
+		order: model.Order{
+			ConsignmentID: "CON202501004", MerchantOrderID: "MERCHANT004",
+			RecipientName: "Sarah Wilson", RecipientPhone: "01744444444", RecipientAddress: "Flat 3A, Building 7, Mirpur 12",
+			RecipientArea: "DOHS Area", OrderType: "delivery",
+			ItemQuantity: 5, ItemWeight: 1.5, ItemDescription: "Programming Books Collection", SpecialInstruction: "Educational books",
+			OrderAmount: 2500 * money.Taka, OrderStatus: "in_transit",
+		},
+		store: "Book Corner", city: "Dhaka", zone: "Mirpur", deliveryType: "Standard Delivery", itemType: "Books",
+	},
+	{
+		order: model.Order{
+			ConsignmentID: "CON202501005", MerchantOrderID: "MERCHANT005",
+			RecipientName: "David Brown", RecipientPhone: "01755555555", RecipientAddress: "House 12, Lane 8, Wari",
+			RecipientArea: "Old Dhaka Area", OrderType: "delivery",
+			ItemQuantity: 1, ItemWeight: 5, ItemDescription: "Microwave Oven", SpecialInstruction: "Fragile item",
+			OrderAmount: 15000 * money.Taka, OrderStatus: "delivered",
+		},
+		store: "Electronics Hub", city: "Dhaka", zone: "Wari", deliveryType: "Next Day Delivery", itemType: "Home Appliances",
+	},
+	{
+		order: model.Order{
+			ConsignmentID: "CON202501006", MerchantOrderID: "RETURN001",
+			RecipientName: "John Doe", RecipientPhone: "01711111111", RecipientAddress: "House 10, Road 15, Block C",
+			RecipientArea: "Near Dhanmondi Lake", OrderType: "return",
+			ItemQuantity: 1, ItemWeight: 0.5, ItemDescription: "Defective Phone Case", SpecialInstruction: "Product return - defective",
+			OrderAmount: 0, OrderStatus: "pending",
+		},
+		store: "Tech World Dhaka", city: "Dhaka", zone: "Dhanmondi", deliveryType: "Express Delivery", itemType: "Electronics",
+	},
+}
+
+// seedDemo upserts the demo user, stores and orders, resetting any changes
+// made to them since the last run
+func seedDemo(ctx context.Context, s *Seeder, tx *gorm.DB) error {
+	userID, err := upsertUser(ctx, tx, DemoUserEmail, DemoUserPassword, consts.RoleMerchant)
+	if err != nil {
+		return err
+	}
+
+	if _, err := upsertUser(ctx, tx, DemoAdminEmail, DemoAdminPassword, consts.RoleAdmin); err != nil {
+		return err
+	}
+
+	storeIDs := make(map[string]int64, len(demoStores))
+	for _, store := range demoStores {
+		id, err := upsertStore(ctx, tx, store)
+		if err != nil {
+			return err
+		}
+		storeIDs[store.Name] = id
+	}
+
+	refs, err := loadReferences(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	orders := make([]model.Order, 0, len(demoOrders))
+	for _, demo := range demoOrders {
+		city, ok := refs.cities[demo.city]
+		if !ok {
+			return fmt.Errorf("demo order %s: unknown city %q", demo.order.ConsignmentID, demo.city)
+		}
+		zoneID, ok := refs.zones[zoneKey{city.ID, demo.zone}]
+		if !ok {
+			return fmt.Errorf("demo order %s: unknown zone %q", demo.order.ConsignmentID, demo.zone)
+		}
+
+		order := demo.order
+		order.UserID = userID
+		order.StoreID = storeIDs[demo.store]
+		order.RecipientCity = city.ID
+		order.RecipientZone = zoneID
+		order.DeliveryTypeID = refs.deliveryTypes[demo.deliveryType]
+		order.ItemType = refs.itemTypes[demo.itemType]
+		orders = append(orders, priceOrder(order, city.BaseDeliveryFee))
+	}
+
+	if err := upsertOrders(ctx, tx, orders); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// upsertUser creates the user or resets its password and role, returning
+// its id. Seeded users are verified and unlocked so they can log in.
+func upsertUser(ctx context.Context, tx *gorm.DB, email, password, role string) (int64, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, fmt.Errorf("failed to hash password for %s: %w", email, err)
+	}
+
+	verifiedAt := time.Now()
+	user := model.User{Email: email, PasswordHash: string(hash), Role: role, EmailVerifiedAt: &verifiedAt}
+	err = tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "email"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"password_hash":     user.PasswordHash,
+			"role":              role,
+			"email_verified_at": gorm.Expr("COALESCE(users.email_verified_at, EXCLUDED.email_verified_at)"),
+			"locked_until":      nil,
+			"lockout_count":     0,
+		}),
+	}).Create(&user).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to seed user %s: %w", email, err)
+	}
+
+	if err := tx.WithContext(ctx).Where("email = ?", email).Take(&user).Error; err != nil {
+		return 0, fmt.Errorf("failed to load user %s: %w", email, err)
+	}
+
+	return user.ID, nil
+}
+
+// upsertStore matches stores by name since store names are not unique in
+// general, only within the seed data
+func upsertStore(ctx context.Context, tx *gorm.DB, store model.Store) (int64, error) {
+	err := tx.WithContext(ctx).
+		Where("name = ?", store.Name).
+		Assign(model.Store{ContactPhone: store.ContactPhone, Address: store.Address}).
+		FirstOrCreate(&store).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to seed store %s: %w", store.Name, err)
+	}
+
+	return store.ID, nil
+}
+
+func upsertOrders(ctx context.Context, tx *gorm.DB, orders []model.Order) error {
+	err := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "consignment_id"}}, UpdateAll: true}).
+		CreateInBatches(&orders, 500).Error
+	if err != nil {
+		return fmt.Errorf("failed to seed orders: %w", err)
+	}
+
+	return nil
+}
+
+// priceOrder fills in the fees the same way the order service does
+func priceOrder(order model.Order, baseDeliveryFee money.Amount) model.Order {
+	pricing.Apply(&order, baseDeliveryFee)
+	return order
+}
+
+type zoneKey struct {
+	cityID int64
+	name   string
+}
+
+// references holds the reference rows orders point at, keyed by name
+type references struct {
+	cities        map[string]model.City
+	zones         map[zoneKey]int64
+	zoneList      []model.Zone
+	itemTypes     map[string]int64
+	deliveryTypes map[string]int64
+}
+
+func loadReferences(ctx context.Context, tx *gorm.DB) (references, error) {
+	refs := references{
+		cities: make(map[string]model.City),
+		zones:  make(map[zoneKey]int64),
+	}
+
+	var cities []model.City
+	if err := tx.WithContext(ctx).Find(&cities).Error; err != nil {
+		return refs, fmt.Errorf("failed to load cities: %w", err)
+	}
+	for _, city := range cities {
+		refs.cities[city.Name] = city
+	}
+
+	if err := tx.WithContext(ctx).Order("id").Find(&refs.zoneList).Error; err != nil {
+		return refs, fmt.Errorf("failed to load zones: %w", err)
+	}
+	for _, zone := range refs.zoneList {
+		refs.zones[zoneKey{zone.CityID, zone.Name}] = zone.ID
+	}
+
+	var err error
+	if refs.itemTypes, err = idsByName(ctx, tx, "item_types"); err != nil {
+		return refs, err
+	}
+	if refs.deliveryTypes, err = idsByName(ctx, tx, "delivery_types"); err != nil {
+		return refs, err
+	}
+
+	return refs, nil
+}
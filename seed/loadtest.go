@@ -0,0 +1,110 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"oms/consts"
+	"oms/model"
+	"oms/money"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+const (
+	loadtestUserEmail    = "loadtest@oms.local"
+	loadtestUserPassword = "loadtest1234"
+	loadtestStores       = 25
+)
+
+var loadtestStatuses = []string{
+	"pending", "confirmed", "picked_up", "in_transit", "out_for_delivery",
+	"delivered", "failed_delivery", "returned", "cancelled",
+}
+
+// seedLoadtest generates orders spread over every district and thana. A
+// fixed random seed keeps the data identical between runs, so re-running it
+// updates the same consignments instead of adding new ones.
+func seedLoadtest(ctx context.Context, s *Seeder, tx *gorm.DB) error {
+	userID, err := upsertUser(ctx, tx, loadtestUserEmail, loadtestUserPassword, consts.RoleMerchant)
+	if err != nil {
+		return err
+	}
+
+	storeIDs := make([]int64, 0, loadtestStores)
+	for i := 1; i <= loadtestStores; i++ {
+		id, err := upsertStore(ctx, tx, model.Store{
+			Name:         fmt.Sprintf("Load Test Store %02d", i),
+			ContactPhone: fmt.Sprintf("0170000%04d", i),
+			Address:      fmt.Sprintf("Load Test Lane %d, Dhaka", i),
+		})
+		if err != nil {
+			return err
+		}
+		storeIDs = append(storeIDs, id)
+	}
+
+	refs, err := loadReferences(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if len(refs.zoneList) == 0 || len(refs.itemTypes) == 0 || len(refs.deliveryTypes) == 0 {
+		return fmt.Errorf("reference data is missing, seed the %s set first", SetReference)
+	}
+
+	fees := make(map[int64]money.Amount, len(refs.cities))
+	for _, city := range refs.cities {
+		fees[city.ID] = city.BaseDeliveryFee
+	}
+	itemTypeIDs := sortedIDs(refs.itemTypes)
+	deliveryTypeIDs := sortedIDs(refs.deliveryTypes)
+
+	random := rand.New(rand.NewSource(1))
+	orders := make([]model.Order, 0, s.loadtestOrders)
+	for i := 1; i <= s.loadtestOrders; i++ {
+		zone := refs.zoneList[random.Intn(len(refs.zoneList))]
+		weight := math.Round((0.2+random.Float64()*9.8)*100) / 100
+
+		order := model.Order{
+			ConsignmentID:    fmt.Sprintf("LT%010d", i),
+			UserID:           userID,
+			StoreID:          storeIDs[random.Intn(len(storeIDs))],
+			MerchantOrderID:  fmt.Sprintf("LOADTEST%07d", i),
+			RecipientName:    fmt.Sprintf("Load Test Recipient %d", i),
+			RecipientPhone:   fmt.Sprintf("018%08d", random.Intn(100000000)),
+			RecipientAddress: fmt.Sprintf("House %d, Road %d", 1+random.Intn(200), 1+random.Intn(50)),
+			RecipientCity:    zone.CityID,
+			RecipientZone:    zone.ID,
+			OrderType:        "delivery",
+			DeliveryTypeID:   deliveryTypeIDs[random.Intn(len(deliveryTypeIDs))],
+			ItemType:         itemTypeIDs[random.Intn(len(itemTypeIDs))],
+			ItemQuantity:     1 + random.Intn(5),
+			ItemWeight:       weight,
+			ItemDescription:  "Load test parcel",
+			OrderAmount:      money.Amount(100+random.Intn(20000)) * money.Taka,
+			OrderStatus:      loadtestStatuses[random.Intn(len(loadtestStatuses))],
+		}
+		if order.OrderStatus == consts.OrderStatusCancelled {
+			order.CancellationReason = "customer_request"
+		}
+		orders = append(orders, priceOrder(order, fees[zone.CityID]))
+	}
+
+	return upsertOrders(ctx, tx, orders)
+}
+
+// sortedIDs returns the ids of a name lookup in a stable order
+func sortedIDs(ids map[string]int64) []int64 {
+	values := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+
+	return values
+}
@@ -0,0 +1,154 @@
+package seed
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"oms/model"
+	"oms/money"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:embed data/*.json
+var datasets embed.FS
+
+// District is a district of Bangladesh with its thanas, as shipped in
+// data/bd_districts.json. Districts are stored as cities and thanas as zones.
+type District struct {
+	Name            string       `json:"name"`
+	Division        string       `json:"division"`
+	BaseDeliveryFee money.Amount `json:"base_delivery_fee"`
+	Thanas          []string     `json:"thanas"`
+}
+
+var itemTypes = []string{
+	"Electronics", "Clothing", "Food & Beverage", "Documents", "Cosmetics",
+	"Books", "Home Appliances", "Jewelry", "Toys", "Medicine",
+}
+
+var deliveryTypes = []string{
+	"Standard Delivery", "Express Delivery", "Same Day Delivery",
+	"Next Day Delivery", "Urgent Delivery",
+}
+
+// cancellationReasons are the reasons merchants pick from when cancelling
+// an order. Other needs a note saying what the reason was.
+var cancellationReasons = []model.CancellationReason{
+	{Code: "customer_request", Description: "Customer asked to cancel"},
+	{Code: "duplicate_order", Description: "Order was placed twice"},
+	{Code: "wrong_address", Description: "Recipient address is wrong"},
+	{Code: "out_of_stock", Description: "Item is out of stock"},
+	{Code: "recipient_unreachable", Description: "Recipient cannot be reached"},
+	{Code: "price_dispute", Description: "Recipient disputes the price"},
+	{Code: "other", Description: "Other reason", NoteRequired: true},
+}
+
+// Districts returns the bundled district and thana dataset
+func Districts() ([]District, error) {
+	raw, err := datasets.ReadFile("data/bd_districts.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read district dataset: %w", err)
+	}
+
+	var dataset struct {
+		Districts []District `json:"districts"`
+	}
+	if err := json.Unmarshal(raw, &dataset); err != nil {
+		return nil, fmt.Errorf("failed to parse district dataset: %w", err)
+	}
+
+	return dataset.Districts, nil
+}
+
+// seedReference inserts missing reference rows. Existing rows are left
+// alone so delivery fees tuned through the API are not reset.
+func seedReference(ctx context.Context, s *Seeder, tx *gorm.DB) error {
+	districts, err := Districts()
+	if err != nil {
+		return err
+	}
+
+	cities := make([]model.City, 0, len(districts))
+	for _, district := range districts {
+		cities = append(cities, model.City{Name: district.Name, BaseDeliveryFee: district.BaseDeliveryFee})
+	}
+	if err := tx.WithContext(ctx).Clauses(insertMissing("name")).Create(&cities).Error; err != nil {
+		return fmt.Errorf("failed to seed cities: %w", err)
+	}
+
+	cityIDs, err := idsByName(ctx, tx, "cities")
+	if err != nil {
+		return err
+	}
+
+	var zones []model.Zone
+	for _, district := range districts {
+		for _, thana := range district.Thanas {
+			zones = append(zones, model.Zone{CityID: cityIDs[district.Name], Name: thana})
+		}
+	}
+	if err := tx.WithContext(ctx).Clauses(insertMissing("city_id", "name")).CreateInBatches(&zones, 500).Error; err != nil {
+		return fmt.Errorf("failed to seed zones: %w", err)
+	}
+
+	items := make([]model.ItemType, 0, len(itemTypes))
+	for _, name := range itemTypes {
+		items = append(items, model.ItemType{Name: name})
+	}
+	if err := tx.WithContext(ctx).Clauses(insertMissing("name")).Create(&items).Error; err != nil {
+		return fmt.Errorf("failed to seed item types: %w", err)
+	}
+
+	deliveries := make([]model.DeliveryType, 0, len(deliveryTypes))
+	for _, name := range deliveryTypes {
+		deliveries = append(deliveries, model.DeliveryType{Name: name})
+	}
+	if err := tx.WithContext(ctx).Clauses(insertMissing("name")).Create(&deliveries).Error; err != nil {
+		return fmt.Errorf("failed to seed delivery types: %w", err)
+	}
+
+	reasons := append([]model.CancellationReason(nil), cancellationReasons...)
+	if err := tx.WithContext(ctx).Clauses(insertMissing("code")).Create(&reasons).Error; err != nil {
+		return fmt.Errorf("failed to seed cancellation reasons: %w", err)
+	}
+
+	log.Printf("Reference data covers %d districts, %d thanas, %d item types, %d delivery types and %d cancellation reasons",
+		len(cities), len(zones), len(items), len(deliveries), len(reasons))
+	return nil
+}
+
+// insertMissing skips rows that already exist, matching on the partial
+// unique indexes over live (not deleted) reference rows
+func insertMissing(columns ...string) clause.OnConflict {
+	target := make([]clause.Column, 0, len(columns))
+	for _, column := range columns {
+		target = append(target, clause.Column{Name: column})
+	}
+
+	return clause.OnConflict{
+		Columns:     target,
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}
+}
+
+func idsByName(ctx context.Context, tx *gorm.DB, table string) (map[string]int64, error) {
+	var rows []struct {
+		ID   int64
+		Name string
+	}
+	if err := tx.WithContext(ctx).Table(table).Where("deleted_at IS NULL").Select("id", "name").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", table, err)
+	}
+
+	ids := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		ids[row.Name] = row.ID
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,163 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// SetReference is lookup data every environment needs: districts and
+	// thanas, item types, delivery types and cancellation reasons
+	SetReference = "reference"
+	// SetDemo is a demo user with a handful of stores and orders
+	SetDemo = "demo"
+	// SetLoadtest is a large generated order volume for performance testing
+	SetLoadtest = "loadtest"
+)
+
+// DefaultLoadtestOrders is how many orders the loadtest set generates
+const DefaultLoadtestOrders = 10000
+
+// Set is a named group of seed data. Sets upsert their rows, so running one
+// again converges on the same data instead of creating duplicates.
+type Set struct {
+	Name        string
+	Description string
+	DependsOn   []string
+	run         func(ctx context.Context, s *Seeder, tx *gorm.DB) error
+}
+
+var registry = map[string]Set{
+	SetReference: {
+		Name:        SetReference,
+		Description: "64 districts with their thanas, item types, delivery types and cancellation reasons",
+		run:         seedReference,
+	},
+	SetDemo: {
+		Name:        SetDemo,
+		Description: "demo user, stores and sample orders",
+		DependsOn:   []string{SetReference},
+		run:         seedDemo,
+	},
+	SetLoadtest: {
+		Name:        SetLoadtest,
+		Description: "generated stores and orders for load testing",
+		DependsOn:   []string{SetReference},
+		run:         seedLoadtest,
+	},
+}
+
+// Sets returns every known seed set sorted by name
+func Sets() []Set {
+	sets := make([]Set, 0, len(registry))
+	for _, set := range registry {
+		sets = append(sets, set)
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Name < sets[j].Name
+	})
+
+	return sets
+}
+
+// Seeder loads seed sets into the database
+type Seeder struct {
+	db             *gorm.DB
+	loadtestOrders int
+}
+
+// NewSeeder creates a seeder writing through db
+func NewSeeder(db *gorm.DB) *Seeder {
+	return &Seeder{
+		db:             db,
+		loadtestOrders: DefaultLoadtestOrders,
+	}
+}
+
+// SetLoadtestOrders sets how many orders the loadtest set generates
+func (s *Seeder) SetLoadtestOrders(n int) {
+	if n > 0 {
+		s.loadtestOrders = n
+	}
+}
+
+// Run seeds the named sets along with the sets they depend on. Each set runs
+// in its own transaction.
+func (s *Seeder) Run(ctx context.Context, names ...string) error {
+	ordered, err := resolve(names)
+	if err != nil {
+		return err
+	}
+
+	for _, set := range ordered {
+		log.Printf("Seeding %s data", set.Name)
+
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return set.run(ctx, s, tx)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to seed %s data: %w", set.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ParseSets splits a comma separated list of set names such as
+// "reference,demo", ignoring blanks
+func ParseSets(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// resolve orders the requested sets so dependencies come first, each set once
+func resolve(names []string) ([]Set, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no seed sets given")
+	}
+
+	var ordered []Set
+	visited := make(map[string]bool)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visited[name] {
+			return nil
+		}
+
+		set, ok := registry[name]
+		if !ok {
+			return fmt.Errorf("unknown seed set %q", name)
+		}
+		visited[name] = true
+
+		for _, dependency := range set.DependsOn {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+
+		ordered = append(ordered, set)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
@@ -1,57 +1,318 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"oms/apperror"
+	"oms/config"
+	"oms/consts"
 	"oms/domain"
+	"oms/i18n"
+	"oms/model"
+	"oms/notification"
 	"oms/types"
+	"oms/utility"
+	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// dummyPasswordHash is compared against when the email is unknown so the
+// response takes as long as for a wrong password
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("oms-dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
 type authService struct {
-	userRepository     domain.UserRepository
-	userSessionService domain.UserSessionService
+	userRepository                   domain.UserRepository
+	loginAttemptRepository           domain.LoginAttemptRepository
+	passwordResetTokenRepository     domain.PasswordResetTokenRepository
+	emailVerificationTokenRepository domain.EmailVerificationTokenRepository
+	userSessionService               domain.UserSessionService
+	mfaService                       domain.MFAService
+	notifier                         notification.Notifier
+	config                           config.Config
 }
 
-func NewAuthService(userRepository domain.UserRepository, userSessionService domain.UserSessionService) domain.AuthService {
+func NewAuthService(
+	userRepository domain.UserRepository,
+	loginAttemptRepository domain.LoginAttemptRepository,
+	passwordResetTokenRepository domain.PasswordResetTokenRepository,
+	emailVerificationTokenRepository domain.EmailVerificationTokenRepository,
+	userSessionService domain.UserSessionService,
+	mfaService domain.MFAService,
+	notifier notification.Notifier,
+	config config.Config,
+) domain.AuthService {
 	return &authService{
-		userRepository:     userRepository,
-		userSessionService: userSessionService,
+		userRepository:                   userRepository,
+		loginAttemptRepository:           loginAttemptRepository,
+		passwordResetTokenRepository:     passwordResetTokenRepository,
+		emailVerificationTokenRepository: emailVerificationTokenRepository,
+		userSessionService:               userSessionService,
+		mfaService:                       mfaService,
+		notifier:                         notifier,
+		config:                           config,
 	}
 }
 
-func (as authService) Login(loginRequest types.UserLoginRequest) (types.UserLoginResponse, error) {
-	user, err := as.userRepository.GetUserByEmail(loginRequest.Email)
+func (as authService) Login(ctx context.Context, loginRequest types.UserLoginRequest) (types.UserLoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "authService.Login")
+	defer span.End()
+
+	// Emails are stored normalized, and every spelling of one must share
+	// the same failure count
+	email := strings.ToLower(strings.TrimSpace(loginRequest.Email))
+
+	now := time.Now()
+	windowStart := now.Add(-as.config.LoginFailureWindow)
+	attempt := model.LoginAttempt{
+		Email:     email,
+		IPAddress: loginRequest.IPAddress,
+		UserAgent: truncate(loginRequest.UserAgent, 512),
+	}
+
+	ipFailures, err := as.loginAttemptRepository.CountFailuresByIP(ctx, loginRequest.IPAddress, windowStart)
+	if err != nil {
+		return types.UserLoginResponse{}, fmt.Errorf("failed to check login attempts: %w", err)
+	}
+	if ipFailures >= int64(as.config.LoginMaxIPFailures) {
+		as.recordAttempt(ctx, attempt, consts.LoginFailureIPBlocked)
+		return types.UserLoginResponse{}, loginThrottled(as.config.LoginFailureWindow)
+	}
+
+	user, err := as.userRepository.GetUserByEmail(ctx, email)
+	if err != nil && !apperror.IsNotFound(err) {
+		return types.UserLoginResponse{}, err
+	}
 	if err != nil {
-		return types.UserLoginResponse{}, fmt.Errorf("invalid email or password")
+		// Unknown emails fail and lock out like known ones so the response
+		// does not reveal which emails have accounts
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(loginRequest.Password))
+		as.recordAttempt(ctx, attempt, consts.LoginFailureInvalidCredentials)
+
+		failures, err := as.loginAttemptRepository.CountFailuresByEmail(ctx, email, windowStart)
+		if err == nil && failures >= int64(as.config.LoginMaxFailures) {
+			return types.UserLoginResponse{}, accountLocked(as.config.LoginLockoutDuration)
+		}
+		return types.UserLoginResponse{}, apperror.Unauthorized("invalid email or password")
+	}
+	attempt.UserID = &user.ID
+
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		as.recordAttempt(ctx, attempt, consts.LoginFailureAccountLocked)
+		return types.UserLoginResponse{}, accountLocked(user.LockedUntil.Sub(now))
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(loginRequest.Password))
 	if err != nil {
-		return types.UserLoginResponse{}, fmt.Errorf("invalid email or password")
+		return types.UserLoginResponse{}, as.failLogin(ctx, user, attempt, consts.LoginFailureInvalidCredentials, now,
+			apperror.Unauthorized("invalid email or password"))
+	}
+
+	// Checked after the password so the response does not reveal that an
+	// unverified account exists to someone without its password
+	if user.EmailVerifiedAt == nil {
+		as.recordAttempt(ctx, attempt, consts.LoginFailureEmailNotVerified)
+		return types.UserLoginResponse{}, apperror.Forbidden("email not verified")
 	}
 
-	session, err := as.userSessionService.CreateUserSession(user.ID)
+	// With MFA enabled the password only earns a challenge. Nothing is
+	// recorded yet: a success would reset the failure count that guards the
+	// second factor.
+	mfaStatus, err := as.mfaService.Status(ctx, user.ID)
 	if err != nil {
-		return types.UserLoginResponse{}, fmt.Errorf("failed to create session")
+		return types.UserLoginResponse{}, fmt.Errorf("failed to check mfa: %w", err)
+	}
+	if mfaStatus.Enabled {
+		challenge, err := utility.GenerateMFAChallenge(as.config.JWTSecret, user.ID, as.config.MFAChallengeTTL)
+		if err != nil {
+			return types.UserLoginResponse{}, fmt.Errorf("failed to create mfa challenge: %w", err)
+		}
+		return types.UserLoginResponse{MFARequired: true, MFAToken: challenge}, nil
+	}
+
+	return as.completeLogin(ctx, user, attempt, false)
+}
+
+// LoginMFA finishes a login that returned an MFA challenge, using a code
+// from the authenticator app or a recovery code
+func (as authService) LoginMFA(ctx context.Context, request types.MFALoginRequest) (types.UserLoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "authService.LoginMFA")
+	defer span.End()
+
+	claims, err := utility.VerifyMFAChallenge(as.config.JWTSecret, request.MFAToken)
+	if err != nil {
+		return types.UserLoginResponse{}, apperror.Unauthorized("invalid or expired mfa token")
+	}
+
+	user, err := as.userRepository.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return types.UserLoginResponse{}, apperror.Unauthorized("invalid or expired mfa token")
+	}
+
+	now := time.Now()
+	attempt := model.LoginAttempt{
+		Email:     user.Email,
+		UserID:    &user.ID,
+		IPAddress: request.IPAddress,
+		UserAgent: truncate(request.UserAgent, 512),
+	}
+
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		as.recordAttempt(ctx, attempt, consts.LoginFailureAccountLocked)
+		return types.UserLoginResponse{}, accountLocked(user.LockedUntil.Sub(now))
+	}
+
+	ok, err := as.mfaService.Verify(ctx, user.ID, request.Code, request.RecoveryCode)
+	if err != nil {
+		return types.UserLoginResponse{}, fmt.Errorf("failed to verify mfa code: %w", err)
+	}
+	if !ok {
+		return types.UserLoginResponse{}, as.failLogin(ctx, user, attempt, consts.LoginFailureInvalidMFACode, now,
+			apperror.Unauthorized("invalid mfa code"))
+	}
+
+	return as.completeLogin(ctx, user, attempt, true)
+}
+
+// completeLogin records the successful attempt, lifts the lockout doubling
+// and starts a session
+func (as authService) completeLogin(ctx context.Context, user model.User, attempt model.LoginAttempt, mfaVerified bool) (types.UserLoginResponse, error) {
+	as.recordAttempt(ctx, attempt, "")
+	if user.LockedUntil != nil || user.LockoutCount > 0 {
+		if err := as.userRepository.ResetLockout(ctx, user.ID); err != nil {
+			log.Printf("Failed to reset lockout for user %d: %v", user.ID, err)
+		}
+	}
+
+	session, err := as.userSessionService.CreateUserSession(ctx, user.ID, mfaVerified)
+	if err != nil {
+		return types.UserLoginResponse{}, fmt.Errorf("failed to create session: %w", err)
 	}
 
 	return types.UserLoginResponse{
 		AccessToken:  session.AccessToken,
 		RefreshToken: session.RefreshToken,
-		ExpiresAt:    session.ExpiresAt,
+		ExpiresAt:    &session.ExpiresAt,
 		TokenType:    "Bearer",
 	}, nil
 }
 
-func (as authService) Logout(accessToken string) error {
-	_, err := as.userSessionService.ValidateSession(accessToken)
+// failLogin records a wrong password or code and locks the account once
+// there are too many. It returns failure unless the account got locked.
+func (as authService) failLogin(ctx context.Context, user model.User, attempt model.LoginAttempt, reason string, now time.Time, failure error) error {
+	as.recordAttempt(ctx, attempt, reason)
+
+	// Failures before the last lockout ended have already been punished
+	since := now.Add(-as.config.LoginFailureWindow)
+	if user.LockedUntil != nil && user.LockedUntil.After(since) {
+		since = *user.LockedUntil
+	}
+
+	failures, err := as.loginAttemptRepository.CountFailuresByEmail(ctx, user.Email, since)
+	if err != nil {
+		return failure
+	}
+	if failures >= int64(as.config.LoginMaxFailures) {
+		return as.lockUser(ctx, user, int(failures), now, failure)
+	}
+
+	return failure
+}
+
+// lockUser locks the account for the lockout duration, doubled for every
+// earlier lockout since the last successful login, and tells the owner
+func (as authService) lockUser(ctx context.Context, user model.User, failures int, now time.Time, failure error) error {
+	duration := as.config.LoginLockoutDuration << min(user.LockoutCount, 16)
+	if duration > as.config.LoginMaxLockoutDuration || duration <= 0 {
+		duration = as.config.LoginMaxLockoutDuration
+	}
+	until := now.Add(duration)
+
+	locked, err := as.userRepository.LockUser(ctx, user.ID, until)
+	if err != nil {
+		log.Printf("Failed to lock user %d: %v", user.ID, err)
+		return failure
+	}
+	if !locked {
+		// A concurrent attempt locked the account first
+		return accountLocked(duration)
+	}
+
+	log.Printf("Locked user %d for %s after %d failed login attempts", user.ID, duration, failures)
+
+	lang := userLanguage(ctx, user)
+	err = as.notifier.Notify(ctx, notification.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, "Your OMS account has been temporarily locked"),
+		Body: i18n.Sprintf(lang, "After %d failed sign-in attempts your account is locked until %s. "+
+			"It unlocks automatically at that time. If these attempts were not yours, change your password once you can sign in.",
+			failures, until.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		log.Printf("Failed to send lockout notification to user %d: %v", user.ID, err)
+	}
+
+	return accountLocked(duration)
+}
+
+// accountLocked is the error of a login to a locked account
+func accountLocked(retryAfter time.Duration) error {
+	return apperror.Locked("account temporarily locked after too many failed login attempts").WithRetryAfter(retryAfter)
+}
+
+// loginThrottled is the error of a login from an address with too many
+// failed attempts
+func loginThrottled(retryAfter time.Duration) error {
+	return apperror.TooManyRequests("too many failed login attempts, please try again later").WithRetryAfter(retryAfter)
+}
+
+// userLanguage is the language to write to user in: their preference, or
+// else the language of the request
+func userLanguage(ctx context.Context, user model.User) string {
+	if user.Language != "" {
+		return user.Language
+	}
+	return i18n.FromContext(ctx)
+}
+
+// recordAttempt stores a login attempt. An empty failureReason marks a
+// success. Failing to record does not fail the login.
+func (as authService) recordAttempt(ctx context.Context, attempt model.LoginAttempt, failureReason string) {
+	attempt.Succeeded = failureReason == ""
+	attempt.FailureReason = failureReason
+
+	if err := as.loginAttemptRepository.CreateLoginAttempt(ctx, attempt); err != nil {
+		log.Printf("Failed to record login attempt for %s: %v", attempt.Email, err)
+	}
+}
+
+// truncate shortens value to at most length characters
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+
+	return string(runes[:length])
+}
+
+func (as authService) Logout(ctx context.Context, accessToken string) error {
+	ctx, span := tracer.Start(ctx, "authService.Logout")
+	defer span.End()
+
+	_, err := as.userSessionService.ValidateSession(ctx, accessToken)
 	if err != nil {
-		return fmt.Errorf("invalid access token")
+		return err
 	}
 
 	// Invalidate the session
-	err = as.userSessionService.InvalidateSession(accessToken)
+	err = as.userSessionService.InvalidateSession(ctx, accessToken)
 	if err != nil {
 		return fmt.Errorf("failed to logout: %w", err)
 	}
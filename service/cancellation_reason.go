@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"oms/apperror"
+	"oms/domain"
+	"oms/model"
+	"oms/types"
+	"strings"
+)
+
+type cancellationReasonService struct {
+	cancellationReasonRepository domain.CancellationReasonRepository
+}
+
+func NewCancellationReasonService(cancellationReasonRepository domain.CancellationReasonRepository) domain.CancellationReasonService {
+	return &cancellationReasonService{cancellationReasonRepository: cancellationReasonRepository}
+}
+
+func (crs cancellationReasonService) CreateCancellationReason(ctx context.Context, reason types.CancellationReasonCreateRequest) error {
+	ctx, span := tracer.Start(ctx, "cancellationReasonService.CreateCancellationReason")
+	defer span.End()
+
+	code := normalizeReasonCode(reason.Code)
+	if code == "" {
+		return apperror.Validation("cancellation reason code cannot be empty")
+	}
+
+	existing, err := crs.cancellationReasonRepository.GetCancellationReasonByCode(ctx, code)
+	if err == nil && existing.ID != 0 {
+		return apperror.Conflict("cancellation reason '%s' already exists", code)
+	}
+	if err != nil && !apperror.IsNotFound(err) {
+		return err
+	}
+
+	return crs.cancellationReasonRepository.CreateCancellationReason(ctx, model.CancellationReason{
+		Code:         code,
+		Description:  strings.TrimSpace(reason.Description),
+		NoteRequired: reason.NoteRequired,
+	})
+}
+
+func (crs cancellationReasonService) GetCancellationReasonByID(ctx context.Context, id int64) (types.CancellationReasonResponse, error) {
+	ctx, span := tracer.Start(ctx, "cancellationReasonService.GetCancellationReasonByID")
+	defer span.End()
+
+	existingReason, err := crs.cancellationReasonRepository.GetCancellationReasonByID(ctx, id)
+	if err != nil {
+		return types.CancellationReasonResponse{}, err
+	}
+
+	return mapCancellationReasonToResponse(existingReason), nil
+}
+
+func (crs cancellationReasonService) GetAllCancellationReasons(ctx context.Context, limit, offset int) ([]types.CancellationReasonResponse, error) {
+	ctx, span := tracer.Start(ctx, "cancellationReasonService.GetAllCancellationReasons")
+	defer span.End()
+
+	existingReasons, err := crs.cancellationReasonRepository.GetAllCancellationReasons(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.CancellationReasonResponse
+	for _, existingReason := range existingReasons {
+		result = append(result, mapCancellationReasonToResponse(existingReason))
+	}
+
+	return result, nil
+}
+
+func (crs cancellationReasonService) UpdateCancellationReason(ctx context.Context, reason types.CancellationReasonUpdateRequest) error {
+	ctx, span := tracer.Start(ctx, "cancellationReasonService.UpdateCancellationReason")
+	defer span.End()
+
+	existingReason, err := crs.cancellationReasonRepository.GetCancellationReasonByID(ctx, reason.ID)
+	if err != nil {
+		return err
+	}
+
+	code := normalizeReasonCode(reason.Code)
+	if code == "" {
+		return apperror.Validation("cancellation reason code cannot be empty")
+	}
+
+	if code != existingReason.Code {
+		existing, err := crs.cancellationReasonRepository.GetCancellationReasonByCode(ctx, code)
+		if err == nil && existing.ID != 0 && existing.ID != existingReason.ID {
+			return apperror.Conflict("cancellation reason '%s' already exists", code)
+		}
+		if err != nil && !apperror.IsNotFound(err) {
+			return err
+		}
+
+		existingReason.Code = code
+	}
+
+	existingReason.Description = strings.TrimSpace(reason.Description)
+	existingReason.NoteRequired = reason.NoteRequired
+
+	return crs.cancellationReasonRepository.UpdateCancellationReason(ctx, existingReason)
+}
+
+func (crs cancellationReasonService) DeleteCancellationReason(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "cancellationReasonService.DeleteCancellationReason")
+	defer span.End()
+
+	if _, err := crs.cancellationReasonRepository.GetCancellationReasonByID(ctx, id); err != nil {
+		return err
+	}
+
+	return crs.cancellationReasonRepository.DeleteCancellationReason(ctx, id)
+}
+
+// normalizeReasonCode writes codes in lower case with underscores, such as
+// wrong_address, the way orders record them
+func normalizeReasonCode(code string) string {
+	return strings.Join(strings.Fields(strings.ToLower(code)), "_")
+}
+
+func mapCancellationReasonToResponse(reason model.CancellationReason) types.CancellationReasonResponse {
+	return types.CancellationReasonResponse{
+		ID:           reason.ID,
+		Code:         reason.Code,
+		Description:  reason.Description,
+		NoteRequired: reason.NoteRequired,
+		CreatedAt:    reason.CreatedAt,
+		UpdatedAt:    reason.UpdatedAt,
+	}
+}
@@ -1,7 +1,8 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 	"oms/types"
@@ -16,10 +17,16 @@ func NewCityService(cityRepository domain.CityRepository) domain.CityService {
 	return &cityService{cityRepository: cityRepository}
 }
 
-func (cs cityService) CreateCity(city types.CityCreateRequest) error {
-	existing, err := cs.cityRepository.GetCityByName(city.Name)
+func (cs cityService) CreateCity(ctx context.Context, city types.CityCreateRequest) error {
+	ctx, span := tracer.Start(ctx, "cityService.CreateCity")
+	defer span.End()
+
+	existing, err := cs.cityRepository.GetCityByName(ctx, city.Name)
 	if err == nil && existing.ID != 0 {
-		return fmt.Errorf("city with name '%s' already exists", city.Name)
+		return apperror.Conflict("city with name '%s' already exists", city.Name)
+	}
+	if err != nil && !apperror.IsNotFound(err) {
+		return err
 	}
 
 	newCity := model.City{
@@ -27,7 +34,7 @@ func (cs cityService) CreateCity(city types.CityCreateRequest) error {
 		BaseDeliveryFee: city.BaseDeliveryFee,
 	}
 
-	err = cs.cityRepository.CreateCity(newCity)
+	err = cs.cityRepository.CreateCity(ctx, newCity)
 	if err != nil {
 		return err
 	}
@@ -35,8 +42,11 @@ func (cs cityService) CreateCity(city types.CityCreateRequest) error {
 	return nil
 }
 
-func (cs cityService) GetCityByID(id int64) (types.CityResponse, error) {
-	existingCity, err := cs.cityRepository.GetCityByID(id)
+func (cs cityService) GetCityByID(ctx context.Context, id int64) (types.CityResponse, error) {
+	ctx, span := tracer.Start(ctx, "cityService.GetCityByID")
+	defer span.End()
+
+	existingCity, err := cs.cityRepository.GetCityByID(ctx, id)
 	if err != nil {
 		return types.CityResponse{}, err
 	}
@@ -45,11 +55,15 @@ func (cs cityService) GetCityByID(id int64) (types.CityResponse, error) {
 		Name:            existingCity.Name,
 		BaseDeliveryFee: existingCity.BaseDeliveryFee,
 		UpdatedAt:       existingCity.UpdatedAt,
+		Version:         existingCity.Version,
 	}, nil
 }
 
-func (cs cityService) GetAllCities(limit, offset int) ([]types.CityResponse, error) {
-	existingCities, err := cs.cityRepository.GetAllCities(limit, offset)
+func (cs cityService) GetAllCities(ctx context.Context, limit, offset int) ([]types.CityResponse, error) {
+	ctx, span := tracer.Start(ctx, "cityService.GetAllCities")
+	defer span.End()
+
+	existingCities, err := cs.cityRepository.GetAllCities(ctx, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -62,14 +76,18 @@ func (cs cityService) GetAllCities(limit, offset int) ([]types.CityResponse, err
 			Name:            existingCity.Name,
 			BaseDeliveryFee: existingCity.BaseDeliveryFee,
 			UpdatedAt:       existingCity.UpdatedAt,
+			Version:         existingCity.Version,
 		})
 	}
 
 	return result, nil
 }
 
-func (cs cityService) GetCityByName(name string) (types.CityResponse, error) {
-	existingCity, err := cs.cityRepository.GetCityByName(name)
+func (cs cityService) GetCityByName(ctx context.Context, name string) (types.CityResponse, error) {
+	ctx, span := tracer.Start(ctx, "cityService.GetCityByName")
+	defer span.End()
+
+	existingCity, err := cs.cityRepository.GetCityByName(ctx, name)
 	if err != nil {
 		return types.CityResponse{}, err
 	}
@@ -78,19 +96,26 @@ func (cs cityService) GetCityByName(name string) (types.CityResponse, error) {
 		Name:            existingCity.Name,
 		BaseDeliveryFee: existingCity.BaseDeliveryFee,
 		UpdatedAt:       existingCity.UpdatedAt,
+		Version:         existingCity.Version,
 	}, nil
 }
 
-func (cs cityService) UpdateCity(city types.CityUpdateRequest) error {
-	existingCity, err := cs.cityRepository.GetCityByID(city.ID)
+func (cs cityService) UpdateCity(ctx context.Context, city types.CityUpdateRequest) (int64, error) {
+	ctx, span := tracer.Start(ctx, "cityService.UpdateCity")
+	defer span.End()
+
+	existingCity, err := cs.cityRepository.GetCityByID(ctx, city.ID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if city.Name != existingCity.Name {
-		existing, err := cs.cityRepository.GetCityByName(city.Name)
+		existing, err := cs.cityRepository.GetCityByName(ctx, city.Name)
 		if err == nil && existing.ID != 0 {
-			return fmt.Errorf("city with name '%s' already exists", city.Name)
+			return 0, apperror.Conflict("city with name '%s' already exists", city.Name)
+		}
+		if err != nil && !apperror.IsNotFound(err) {
+			return 0, err
 		}
 
 		existingCity.Name = city.Name
@@ -100,19 +125,18 @@ func (cs cityService) UpdateCity(city types.CityUpdateRequest) error {
 		existingCity.BaseDeliveryFee = city.BaseDeliveryFee
 	}
 
-	err = cs.cityRepository.UpdateCity(existingCity)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	// The change applies only if nobody changed the city since the client read it
+	existingCity.Version = city.Version
+	return cs.cityRepository.UpdateCity(ctx, existingCity)
 }
 
-func (cs cityService) DeleteCity(id int64) error {
-	existingCity, err := cs.cityRepository.GetCityByID(id)
-	if err != nil || existingCity.ID == 0 {
-		return fmt.Errorf("city does not exist")
+func (cs cityService) DeleteCity(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "cityService.DeleteCity")
+	defer span.End()
+
+	if _, err := cs.cityRepository.GetCityByID(ctx, id); err != nil {
+		return err
 	}
 
-	return cs.cityRepository.DeleteCity(id)
+	return cs.cityRepository.DeleteCity(ctx, id)
 }
@@ -1,7 +1,8 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 	"oms/types"
@@ -16,24 +17,30 @@ func NewDeliveryTypeService(deliveryTypeRepository domain.DeliveryTypeRepository
 	return &deliveryTypeService{deliveryTypeRepository: deliveryTypeRepository}
 }
 
-func (dts deliveryTypeService) CreateDeliveryType(deliveryType types.DeliveryTypeCreateRequest) error {
+func (dts deliveryTypeService) CreateDeliveryType(ctx context.Context, deliveryType types.DeliveryTypeCreateRequest) error {
+	ctx, span := tracer.Start(ctx, "deliveryTypeService.CreateDeliveryType")
+	defer span.End()
+
 	// Normalize name (trim spaces)
 	normalizedName := strings.TrimSpace(deliveryType.Name)
 	if normalizedName == "" {
-		return fmt.Errorf("delivery type name cannot be empty")
+		return apperror.Validation("delivery type name cannot be empty")
 	}
 
 	// Check if delivery type with same name already exists
-	existing, err := dts.deliveryTypeRepository.GetDeliveryTypeByName(normalizedName)
+	existing, err := dts.deliveryTypeRepository.GetDeliveryTypeByName(ctx, normalizedName)
 	if err == nil && existing.ID != 0 {
-		return fmt.Errorf("delivery type with name '%s' already exists", normalizedName)
+		return apperror.Conflict("delivery type with name '%s' already exists", normalizedName)
+	}
+	if err != nil && !apperror.IsNotFound(err) {
+		return err
 	}
 
 	newDeliveryType := model.DeliveryType{
 		Name: normalizedName,
 	}
 
-	err = dts.deliveryTypeRepository.CreateDeliveryType(newDeliveryType)
+	err = dts.deliveryTypeRepository.CreateDeliveryType(ctx, newDeliveryType)
 	if err != nil {
 		return err
 	}
@@ -41,8 +48,11 @@ func (dts deliveryTypeService) CreateDeliveryType(deliveryType types.DeliveryTyp
 	return nil
 }
 
-func (dts deliveryTypeService) GetDeliveryTypeByID(id int64) (types.DeliveryTypeResponse, error) {
-	existingDeliveryType, err := dts.deliveryTypeRepository.GetDeliveryTypeByID(id)
+func (dts deliveryTypeService) GetDeliveryTypeByID(ctx context.Context, id int64) (types.DeliveryTypeResponse, error) {
+	ctx, span := tracer.Start(ctx, "deliveryTypeService.GetDeliveryTypeByID")
+	defer span.End()
+
+	existingDeliveryType, err := dts.deliveryTypeRepository.GetDeliveryTypeByID(ctx, id)
 	if err != nil {
 		return types.DeliveryTypeResponse{}, err
 	}
@@ -55,8 +65,11 @@ func (dts deliveryTypeService) GetDeliveryTypeByID(id int64) (types.DeliveryType
 	}, nil
 }
 
-func (dts deliveryTypeService) GetAllDeliveryTypes(limit, offset int) ([]types.DeliveryTypeResponse, error) {
-	existingDeliveryTypes, err := dts.deliveryTypeRepository.GetAllDeliveryTypes(limit, offset)
+func (dts deliveryTypeService) GetAllDeliveryTypes(ctx context.Context, limit, offset int) ([]types.DeliveryTypeResponse, error) {
+	ctx, span := tracer.Start(ctx, "deliveryTypeService.GetAllDeliveryTypes")
+	defer span.End()
+
+	existingDeliveryTypes, err := dts.deliveryTypeRepository.GetAllDeliveryTypes(ctx, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +88,11 @@ func (dts deliveryTypeService) GetAllDeliveryTypes(limit, offset int) ([]types.D
 	return result, nil
 }
 
-func (dts deliveryTypeService) UpdateDeliveryType(deliveryType types.DeliveryTypeUpdateRequest) error {
-	existingDeliveryType, err := dts.deliveryTypeRepository.GetDeliveryTypeByID(deliveryType.ID)
+func (dts deliveryTypeService) UpdateDeliveryType(ctx context.Context, deliveryType types.DeliveryTypeUpdateRequest) error {
+	ctx, span := tracer.Start(ctx, "deliveryTypeService.UpdateDeliveryType")
+	defer span.End()
+
+	existingDeliveryType, err := dts.deliveryTypeRepository.GetDeliveryTypeByID(ctx, deliveryType.ID)
 	if err != nil {
 		return err
 	}
@@ -84,20 +100,23 @@ func (dts deliveryTypeService) UpdateDeliveryType(deliveryType types.DeliveryTyp
 	// Normalize name
 	normalizedName := strings.TrimSpace(deliveryType.Name)
 	if normalizedName == "" {
-		return fmt.Errorf("delivery type name cannot be empty")
+		return apperror.Validation("delivery type name cannot be empty")
 	}
 
 	// If name is being changed, check for duplicates
 	if normalizedName != existingDeliveryType.Name {
-		existing, err := dts.deliveryTypeRepository.GetDeliveryTypeByName(normalizedName)
+		existing, err := dts.deliveryTypeRepository.GetDeliveryTypeByName(ctx, normalizedName)
 		if err == nil && existing.ID != 0 && existing.ID != existingDeliveryType.ID {
-			return fmt.Errorf("delivery type with name '%s' already exists", normalizedName)
+			return apperror.Conflict("delivery type with name '%s' already exists", normalizedName)
+		}
+		if err != nil && !apperror.IsNotFound(err) {
+			return err
 		}
 
 		existingDeliveryType.Name = normalizedName
 	}
 
-	err = dts.deliveryTypeRepository.UpdateDeliveryType(existingDeliveryType)
+	err = dts.deliveryTypeRepository.UpdateDeliveryType(ctx, existingDeliveryType)
 	if err != nil {
 		return err
 	}
@@ -105,11 +124,13 @@ func (dts deliveryTypeService) UpdateDeliveryType(deliveryType types.DeliveryTyp
 	return nil
 }
 
-func (dts deliveryTypeService) DeleteDeliveryType(id int64) error {
-	existingDeliveryType, err := dts.deliveryTypeRepository.GetDeliveryTypeByID(id)
-	if err != nil || existingDeliveryType.ID == 0 {
-		return fmt.Errorf("delivery type does not exist")
+func (dts deliveryTypeService) DeleteDeliveryType(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "deliveryTypeService.DeleteDeliveryType")
+	defer span.End()
+
+	if _, err := dts.deliveryTypeRepository.GetDeliveryTypeByID(ctx, id); err != nil {
+		return err
 	}
 
-	return dts.deliveryTypeRepository.DeleteDeliveryType(id)
+	return dts.deliveryTypeRepository.DeleteDeliveryType(ctx, id)
 }
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"oms/config"
+	"oms/consts"
+	"oms/domain"
+	"oms/types"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultHealthCheckTimeout = 2 * time.Second
+
+type healthService struct {
+	healthRepository domain.HealthRepository
+	config           config.Config
+	shuttingDown     atomic.Bool
+}
+
+func NewHealthService(healthRepository domain.HealthRepository, config config.Config) domain.HealthService {
+	return &healthService{
+		healthRepository: healthRepository,
+		config:           config,
+	}
+}
+
+func (hs *healthService) Liveness(ctx context.Context) types.LivenessResponse {
+	return types.LivenessResponse{Status: consts.HealthStatusOK}
+}
+
+func (hs *healthService) Readiness(ctx context.Context) types.ReadinessResponse {
+	if hs.shuttingDown.Load() {
+		return types.ReadinessResponse{
+			Status: consts.HealthStatusShuttingDown,
+			Checks: map[string]types.DependencyStatus{},
+		}
+	}
+
+	checks := map[string]func(context.Context) (map[string]any, error){
+		"master_db":  hs.checkPing(hs.healthRepository.PingMaster),
+		"replica_db": hs.checkReplicas,
+		"redis":      hs.checkPing(hs.healthRepository.PingRedis),
+		"migrations": hs.checkMigrations,
+	}
+
+	timeout := hs.config.HealthCheckTimeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	response := types.ReadinessResponse{
+		Status: consts.HealthStatusOK,
+		Checks: make(map[string]types.DependencyStatus, len(checks)),
+	}
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) (map[string]any, error)) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			details, err := check(checkCtx)
+			status := types.DependencyStatus{
+				Status:    consts.HealthStatusUp,
+				LatencyMs: time.Since(start).Milliseconds(),
+				Details:   details,
+			}
+			if err != nil {
+				status.Status = consts.HealthStatusDown
+				status.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Checks[name] = status
+			if err != nil {
+				response.Status = consts.HealthStatusDegraded
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return response
+}
+
+func (hs *healthService) MarkShuttingDown() {
+	hs.shuttingDown.Store(true)
+}
+
+func (hs *healthService) checkPing(ping func(context.Context) error) func(context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		return nil, ping(ctx)
+	}
+}
+
+// checkReplicas checks every replica, as the router does. One replica down
+// or lagging is reported but not a failure: the router ejects it and the rest,
+// or else the master, serve reads. Only losing every replica degrades.
+func (hs *healthService) checkReplicas(ctx context.Context) (map[string]any, error) {
+	addresses := hs.healthRepository.ReplicaAddresses()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	replicas := make(map[string]any, len(addresses))
+	usable := 0
+
+	for _, address := range addresses {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+
+			status := map[string]any{"status": consts.HealthStatusUp}
+			lag, err := hs.healthRepository.ReplicaLag(ctx, address)
+			switch {
+			case err != nil:
+				status["status"] = consts.HealthStatusDown
+				status["error"] = err.Error()
+			case hs.config.ReplicaMaxLag > 0 && lag > hs.config.ReplicaMaxLag:
+				status["status"] = consts.HealthStatusDown
+				status["lag_seconds"] = lag.Seconds()
+				status["error"] = fmt.Sprintf("replica lag %s exceeds %s", lag, hs.config.ReplicaMaxLag)
+			default:
+				status["lag_seconds"] = lag.Seconds()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			replicas[address] = status
+			if status["status"] == consts.HealthStatusUp {
+				usable++
+			}
+		}(address)
+	}
+	wg.Wait()
+
+	details := map[string]any{"replicas": replicas, "usable": usable}
+	if usable == 0 {
+		return details, fmt.Errorf("none of %d replicas is usable, reads are served by the master", len(addresses))
+	}
+
+	return details, nil
+}
+
+func (hs *healthService) checkMigrations(ctx context.Context) (map[string]any, error) {
+	pending, err := hs.healthRepository.PendingMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pending) > 0 {
+		return map[string]any{"pending": pending}, fmt.Errorf("%d pending migrations", len(pending))
+	}
+
+	return nil, nil
+}
@@ -1,7 +1,8 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 	"oms/types"
@@ -16,24 +17,30 @@ func NewItemTypeService(itemTypeRepository domain.ItemTypeRepository) domain.Ite
 	return &itemTypeService{itemTypeRepository: itemTypeRepository}
 }
 
-func (its itemTypeService) CreateItemType(itemType types.ItemTypeCreateRequest) error {
+func (its itemTypeService) CreateItemType(ctx context.Context, itemType types.ItemTypeCreateRequest) error {
+	ctx, span := tracer.Start(ctx, "itemTypeService.CreateItemType")
+	defer span.End()
+
 	// Normalize name (trim spaces and convert to proper case)
 	normalizedName := strings.TrimSpace(itemType.Name)
 	if normalizedName == "" {
-		return fmt.Errorf("item type name cannot be empty")
+		return apperror.Validation("item type name cannot be empty")
 	}
 
 	// Check if item type with same name already exists (case-insensitive)
-	existing, err := its.itemTypeRepository.GetItemTypeByName(normalizedName)
+	existing, err := its.itemTypeRepository.GetItemTypeByName(ctx, normalizedName)
 	if err == nil && existing.ID != 0 {
-		return fmt.Errorf("item type with name '%s' already exists", normalizedName)
+		return apperror.Conflict("item type with name '%s' already exists", normalizedName)
+	}
+	if err != nil && !apperror.IsNotFound(err) {
+		return err
 	}
 
 	newItemType := model.ItemType{
 		Name: normalizedName,
 	}
 
-	err = its.itemTypeRepository.CreateItemType(newItemType)
+	err = its.itemTypeRepository.CreateItemType(ctx, newItemType)
 	if err != nil {
 		return err
 	}
@@ -41,8 +48,11 @@ func (its itemTypeService) CreateItemType(itemType types.ItemTypeCreateRequest)
 	return nil
 }
 
-func (its itemTypeService) GetItemTypeByID(id int64) (types.ItemTypeResponse, error) {
-	existingItemType, err := its.itemTypeRepository.GetItemTypeByID(id)
+func (its itemTypeService) GetItemTypeByID(ctx context.Context, id int64) (types.ItemTypeResponse, error) {
+	ctx, span := tracer.Start(ctx, "itemTypeService.GetItemTypeByID")
+	defer span.End()
+
+	existingItemType, err := its.itemTypeRepository.GetItemTypeByID(ctx, id)
 	if err != nil {
 		return types.ItemTypeResponse{}, err
 	}
@@ -55,8 +65,11 @@ func (its itemTypeService) GetItemTypeByID(id int64) (types.ItemTypeResponse, er
 	}, nil
 }
 
-func (its itemTypeService) GetAllItemTypes(limit, offset int) ([]types.ItemTypeResponse, error) {
-	existingItemTypes, err := its.itemTypeRepository.GetAllItemTypes(limit, offset)
+func (its itemTypeService) GetAllItemTypes(ctx context.Context, limit, offset int) ([]types.ItemTypeResponse, error) {
+	ctx, span := tracer.Start(ctx, "itemTypeService.GetAllItemTypes")
+	defer span.End()
+
+	existingItemTypes, err := its.itemTypeRepository.GetAllItemTypes(ctx, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +88,11 @@ func (its itemTypeService) GetAllItemTypes(limit, offset int) ([]types.ItemTypeR
 	return result, nil
 }
 
-func (its itemTypeService) UpdateItemType(itemType types.ItemTypeUpdateRequest) error {
-	existingItemType, err := its.itemTypeRepository.GetItemTypeByID(itemType.ID)
+func (its itemTypeService) UpdateItemType(ctx context.Context, itemType types.ItemTypeUpdateRequest) error {
+	ctx, span := tracer.Start(ctx, "itemTypeService.UpdateItemType")
+	defer span.End()
+
+	existingItemType, err := its.itemTypeRepository.GetItemTypeByID(ctx, itemType.ID)
 	if err != nil {
 		return err
 	}
@@ -84,20 +100,23 @@ func (its itemTypeService) UpdateItemType(itemType types.ItemTypeUpdateRequest)
 	// Normalize name
 	normalizedName := strings.TrimSpace(itemType.Name)
 	if normalizedName == "" {
-		return fmt.Errorf("item type name cannot be empty")
+		return apperror.Validation("item type name cannot be empty")
 	}
 
 	// If name is being changed, check for duplicates
 	if normalizedName != existingItemType.Name {
-		existing, err := its.itemTypeRepository.GetItemTypeByName(normalizedName)
+		existing, err := its.itemTypeRepository.GetItemTypeByName(ctx, normalizedName)
 		if err == nil && existing.ID != 0 && existing.ID != existingItemType.ID {
-			return fmt.Errorf("item type with name '%s' already exists", normalizedName)
+			return apperror.Conflict("item type with name '%s' already exists", normalizedName)
+		}
+		if err != nil && !apperror.IsNotFound(err) {
+			return err
 		}
 
 		existingItemType.Name = normalizedName
 	}
 
-	err = its.itemTypeRepository.UpdateItemType(existingItemType)
+	err = its.itemTypeRepository.UpdateItemType(ctx, existingItemType)
 	if err != nil {
 		return err
 	}
@@ -105,11 +124,13 @@ func (its itemTypeService) UpdateItemType(itemType types.ItemTypeUpdateRequest)
 	return nil
 }
 
-func (its itemTypeService) DeleteItemType(id int64) error {
-	existingItemType, err := its.itemTypeRepository.GetItemTypeByID(id)
-	if err != nil || existingItemType.ID == 0 {
-		return fmt.Errorf("item type does not exist")
+func (its itemTypeService) DeleteItemType(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "itemTypeService.DeleteItemType")
+	defer span.End()
+
+	if _, err := its.itemTypeRepository.GetItemTypeByID(ctx, id); err != nil {
+		return err
 	}
 
-	return its.itemTypeRepository.DeleteItemType(id)
+	return its.itemTypeRepository.DeleteItemType(ctx, id)
 }
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"oms/apperror"
+	"oms/config"
+	"oms/consts"
+	"oms/domain"
+	"oms/types"
+	"oms/utility"
+	"strings"
+	"time"
+)
+
+const (
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	// recoveryCodeAlphabet leaves out characters that are easy to misread.
+	// It has 32 characters so random bytes map onto it evenly.
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+)
+
+type mfaService struct {
+	mfaRepository  domain.MFARepository
+	userRepository domain.UserRepository
+	config         config.Config
+}
+
+func NewMFAService(mfaRepository domain.MFARepository, userRepository domain.UserRepository, config config.Config) domain.MFAService {
+	return &mfaService{
+		mfaRepository:  mfaRepository,
+		userRepository: userRepository,
+		config:         config,
+	}
+}
+
+// Enroll starts TOTP enrollment with a new secret. MFA is not enabled until
+// ConfirmEnrollment receives a code generated from it.
+func (ms mfaService) Enroll(ctx context.Context, userID int64) (types.MFAEnrollResponse, error) {
+	ctx, span := tracer.Start(ctx, "mfaService.Enroll")
+	defer span.End()
+
+	user, err := ms.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return types.MFAEnrollResponse{}, err
+	}
+
+	secret, err := utility.GenerateTOTPSecret()
+	if err != nil {
+		return types.MFAEnrollResponse{}, fmt.Errorf("failed to generate mfa secret: %w", err)
+	}
+
+	ciphertext, err := utility.EncryptSecret(ms.config.MFAEncryptionKey, secret)
+	if err != nil {
+		return types.MFAEnrollResponse{}, fmt.Errorf("failed to encrypt mfa secret: %w", err)
+	}
+
+	if err := ms.mfaRepository.SavePendingMFA(ctx, userID, ciphertext); err != nil {
+		return types.MFAEnrollResponse{}, err
+	}
+
+	return types.MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: utility.TOTPProvisioningURI(ms.config.MFAIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables MFA once the user proves their app generates
+// codes for the pending secret, and returns recovery codes. The codes are
+// shown only this once.
+func (ms mfaService) ConfirmEnrollment(ctx context.Context, userID int64, request types.MFACodeRequest) (types.MFARecoveryCodesResponse, error) {
+	ctx, span := tracer.Start(ctx, "mfaService.ConfirmEnrollment")
+	defer span.End()
+
+	mfa, err := ms.mfaRepository.GetUserMFA(ctx, userID)
+	if apperror.IsNotFound(err) {
+		return types.MFARecoveryCodesResponse{}, apperror.Validation("start mfa enrollment first")
+	}
+	if err != nil {
+		return types.MFARecoveryCodesResponse{}, err
+	}
+	if mfa.EnabledAt != nil {
+		return types.MFARecoveryCodesResponse{}, apperror.Conflict("mfa already enabled")
+	}
+
+	secret, err := utility.DecryptSecret(ms.config.MFAEncryptionKey, mfa.SecretCiphertext)
+	if err != nil {
+		return types.MFARecoveryCodesResponse{}, err
+	}
+
+	step, ok := utility.ValidateTOTP(secret, request.Code, time.Now())
+	if !ok {
+		return types.MFARecoveryCodesResponse{}, apperror.Validation("invalid mfa code")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return types.MFARecoveryCodesResponse{}, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := ms.mfaRepository.EnableMFA(ctx, userID, step, hashes); err != nil {
+		return types.MFARecoveryCodesResponse{}, err
+	}
+
+	return types.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns MFA off after checking a code. Users whose role requires
+// MFA cannot turn it off.
+func (ms mfaService) Disable(ctx context.Context, userID int64, request types.MFACodeRequest) error {
+	ctx, span := tracer.Start(ctx, "mfaService.Disable")
+	defer span.End()
+
+	status, err := ms.Status(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if status.Required {
+		return apperror.Forbidden("mfa is required for your role")
+	}
+
+	ok, err := ms.Verify(ctx, userID, request.Code, request.RecoveryCode)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperror.Validation("invalid mfa code")
+	}
+
+	return ms.mfaRepository.DeleteMFA(ctx, userID)
+}
+
+// Status reports whether the user has MFA enabled and whether their role
+// requires it
+func (ms mfaService) Status(ctx context.Context, userID int64) (types.MFAStatusResponse, error) {
+	ctx, span := tracer.Start(ctx, "mfaService.Status")
+	defer span.End()
+
+	user, err := ms.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return types.MFAStatusResponse{}, err
+	}
+
+	status := types.MFAStatusResponse{
+		Required: user.Role == consts.RoleAdmin && ms.config.MFARequiredForAdmin,
+	}
+
+	mfa, err := ms.mfaRepository.GetUserMFA(ctx, userID)
+	if err == nil {
+		status.Enabled = mfa.EnabledAt != nil
+	} else if !apperror.IsNotFound(err) {
+		return types.MFAStatusResponse{}, err
+	}
+
+	return status, nil
+}
+
+// Verify checks a code from the authenticator app, or a recovery code when
+// code is empty. Each code is accepted once.
+func (ms mfaService) Verify(ctx context.Context, userID int64, code, recoveryCode string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "mfaService.Verify")
+	defer span.End()
+
+	mfa, err := ms.mfaRepository.GetUserMFA(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if mfa.EnabledAt == nil {
+		return false, apperror.Validation("mfa not enrolled")
+	}
+
+	if code == "" {
+		return ms.mfaRepository.UseRecoveryCode(ctx, userID, hashRecoveryCode(recoveryCode))
+	}
+
+	secret, err := utility.DecryptSecret(ms.config.MFAEncryptionKey, mfa.SecretCiphertext)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := utility.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return ms.mfaRepository.UseTOTPStep(ctx, userID, step)
+}
+
+// newRecoveryCodes returns codes formatted as xxxxx-xxxxx with their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	buf := make([]byte, recoveryCodeLength)
+	for range recoveryCodeCount {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		var code strings.Builder
+		for i, b := range buf {
+			if i == recoveryCodeLength/2 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+
+		codes = append(codes, code.String())
+		hashes = append(hashes, hashRecoveryCode(code.String()))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so codes can be typed
+// loosely
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
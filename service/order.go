@@ -1,10 +1,16 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"oms/apperror"
+	"oms/config"
 	"oms/consts"
 	"oms/domain"
+	"oms/i18n"
 	"oms/model"
+	"oms/money"
+	"oms/pricing"
 	"oms/types"
 	"time"
 )
@@ -14,36 +20,51 @@ type orderService struct {
 	orderRepository domain.OrderRepository
 	storeService    domain.StoreService
 	cityService     domain.CityService
+	zoneService     domain.ZoneService
+
+	cancellationReasonRepository domain.CancellationReasonRepository
+	cancellationFee              money.Amount
 }
 
+// errOrderForbidden is returned when the order belongs to another merchant
+var errOrderForbidden = apperror.Forbidden("you do not have access to this order")
+
 func NewOrderService(
 	orderRepository domain.OrderRepository,
 	storeService domain.StoreService,
-	cityService domain.CityService) domain.OrderService {
+	cityService domain.CityService,
+	zoneService domain.ZoneService,
+	cancellationReasonRepository domain.CancellationReasonRepository,
+	config config.Config) domain.OrderService {
+	// The fee was checked when the configuration was validated
+	cancellationFee, _ := money.Parse(config.OrderCancellationFee)
+
 	return &orderService{
-		orderRepository: orderRepository,
-		storeService:    storeService,
-		cityService:     cityService,
+		orderRepository:              orderRepository,
+		storeService:                 storeService,
+		cityService:                  cityService,
+		zoneService:                  zoneService,
+		cancellationReasonRepository: cancellationReasonRepository,
+		cancellationFee:              cancellationFee,
 	}
 }
 
-func (os orderService) CreateOrder(order types.OrderCreateRequest) (types.OrderCreateResponse, error) {
-	if _, err := os.storeService.GetStoreByID(order.StoreID); err != nil {
-		return types.OrderCreateResponse{}, fmt.Errorf("invalid store_id: store not found")
+func (os orderService) CreateOrder(ctx context.Context, order types.OrderCreateRequest) (types.OrderCreateResponse, error) {
+	ctx, span := tracer.Start(ctx, "orderService.CreateOrder")
+	defer span.End()
+
+	if _, err := os.storeService.GetStoreByID(ctx, order.StoreID); err != nil {
+		if apperror.IsNotFound(err) {
+			return types.OrderCreateResponse{}, apperror.Validation("Please fix the given errors").WithDetails(map[string][]string{
+				"store_id": {"The store field is required", "Wrong Store selected"},
+			})
+		}
+		return types.OrderCreateResponse{}, err
 	}
 
 	// Generate unique consignment ID
 	consignmentID := generateConsignmentID()
 
-	// Calculate delivery fee (this would typically involve business logic)
-	deliveryFee := os.calculateDeliveryFee(order)
-
-	// Calculate COD fee
-	codFee := calculateCodFee(order.OrderAmount)
-
-	// Calculate total fee
-	totalFee := deliveryFee + codFee - order.PromoDiscount - order.Discount
-
 	newOrder := model.Order{
 		ConsignmentID:      consignmentID,
 		UserID:             order.UserId,
@@ -63,16 +84,13 @@ func (os orderService) CreateOrder(order types.OrderCreateRequest) (types.OrderC
 		ItemDescription:    order.ItemDescription,
 		SpecialInstruction: order.SpecialInstruction,
 		OrderAmount:        order.OrderAmount,
-		AmountToCollect:    order.OrderAmount + totalFee,
-		DeliveryFee:        deliveryFee,
-		CodFee:             codFee,
 		PromoDiscount:      order.PromoDiscount,
 		Discount:           order.Discount,
-		TotalFee:           totalFee,
 		OrderStatus:        consts.OrderStatusPending,
 	}
+	os.priceOrder(ctx, &newOrder)
 
-	err := os.orderRepository.CreateOrder(newOrder)
+	err := os.orderRepository.CreateOrder(ctx, newOrder)
 	if err != nil {
 		return types.OrderCreateResponse{}, err
 	}
@@ -81,27 +99,33 @@ func (os orderService) CreateOrder(order types.OrderCreateRequest) (types.OrderC
 		ConsignmentID:   consignmentID,
 		MerchantOrderID: order.MerchantOrderID,
 		OrderStatus:     newOrder.OrderStatus,
-		DeliveryFee:     deliveryFee,
+		DeliveryFee:     newOrder.DeliveryFee,
 	}
 
 	return response, nil
 }
 
-func (os orderService) GetOrderByConsignmentID(consignmentID string, userId int64) (types.OrderResponse, error) {
-	existingOrder, err := os.orderRepository.GetOrderByConsignmentID(consignmentID)
+func (os orderService) GetOrderByConsignmentID(ctx context.Context, consignmentID string, userId int64) (types.OrderResponse, error) {
+	ctx, span := tracer.Start(ctx, "orderService.GetOrderByConsignmentID")
+	defer span.End()
+
+	existingOrder, err := os.orderRepository.GetOrderByConsignmentID(ctx, consignmentID)
 	if err != nil {
 		return types.OrderResponse{}, err
 	}
 
 	if existingOrder.UserID != userId {
-		return types.OrderResponse{}, fmt.Errorf("unauthorized")
+		return types.OrderResponse{}, errOrderForbidden
 	}
 
 	return os.mapOrderToResponse(existingOrder), nil
 }
 
-func (os orderService) ListAllOrders(listReq types.OrderListRequest) (types.OrderListResponse, error) {
-	orders, pagination, err := os.orderRepository.ListAllOrders(listReq)
+func (os orderService) ListAllOrders(ctx context.Context, listReq types.OrderListRequest) (types.OrderListResponse, error) {
+	ctx, span := tracer.Start(ctx, "orderService.ListAllOrders")
+	defer span.End()
+
+	orders, pagination, err := os.orderRepository.ListAllOrders(ctx, listReq)
 	if err != nil {
 		return types.OrderListResponse{}, err
 	}
@@ -119,17 +143,24 @@ func (os orderService) ListAllOrders(listReq types.OrderListRequest) (types.Orde
 	return response, nil
 }
 
-func (os orderService) UpdateOrder(order types.OrderUpdateRequest) error {
-	existingOrder, err := os.orderRepository.GetOrderByConsignmentID(order.ConsignmentID)
+func (os orderService) UpdateOrder(ctx context.Context, order types.OrderUpdateRequest) (int64, error) {
+	ctx, span := tracer.Start(ctx, "orderService.UpdateOrder")
+	defer span.End()
+
+	existingOrder, err := os.orderRepository.GetOrderByConsignmentID(ctx, order.ConsignmentID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if existingOrder.UserID != order.UserId {
-		return fmt.Errorf("unauthorized")
+		return 0, errOrderForbidden
 	}
+	before := orderPatchOf(existingOrder)
 
 	// Update fields if provided
+	if order.MerchantOrderID != "" {
+		existingOrder.MerchantOrderID = order.MerchantOrderID
+	}
 	if order.RecipientName != "" {
 		existingOrder.RecipientName = order.RecipientName
 	}
@@ -139,48 +170,48 @@ func (os orderService) UpdateOrder(order types.OrderUpdateRequest) error {
 	if order.RecipientAddress != "" {
 		existingOrder.RecipientAddress = order.RecipientAddress
 	}
+	if order.SpecialInstruction != "" {
+		existingOrder.SpecialInstruction = order.SpecialInstruction
+	}
+
 	if order.ItemWeight != 0 {
 		existingOrder.ItemWeight = order.ItemWeight
 	}
 	if order.OrderAmount != 0 {
 		existingOrder.OrderAmount = order.OrderAmount
-		// Recalculate fees
-		existingOrder.DeliveryFee = os.calculateDeliveryFeeFromOrder(existingOrder)
-		existingOrder.CodFee = calculateCodFee(existingOrder.OrderAmount)
-		existingOrder.TotalFee = existingOrder.DeliveryFee + existingOrder.CodFee - existingOrder.PromoDiscount - existingOrder.Discount
-		existingOrder.AmountToCollect = existingOrder.AmountToCollect + existingOrder.TotalFee
-	}
-	if order.SpecialInstruction != "" {
-		existingOrder.SpecialInstruction = order.SpecialInstruction
 	}
 
-	return os.orderRepository.UpdateOrder(existingOrder)
-}
-
-func (os orderService) UpdateOrderStatus(updateReq types.OrderStatusUpdateRequest, status string) error {
-	existingOrder, err := os.orderRepository.GetOrderByConsignmentID(updateReq.ConsignmentID)
-	if err != nil {
-		return err
+	// PUT follows the same per-status rules as PATCH
+	changed := changedOrderFields(before, orderPatchOf(existingOrder))
+	if err := checkOrderEditable(existingOrder.OrderStatus, changed, i18n.FromContext(ctx)); err != nil {
+		return 0, err
 	}
 
-	if existingOrder.UserID != updateReq.UserId {
-		return fmt.Errorf("unauthorized")
+	// Fees follow the weight and the amount, and are worked out from scratch
+	// so repricing the same order twice gives the same result
+	if changed["item_weight"] || changed["order_amount"] {
+		os.priceOrder(ctx, &existingOrder)
 	}
 
-	return os.orderRepository.UpdateOrderStatus(existingOrder.ID, status)
+	// The change applies only if nobody changed the order since the client read it
+	existingOrder.Version = order.Version
+	return os.orderRepository.UpdateOrder(ctx, existingOrder)
 }
 
-func (os orderService) DeleteOrder(consignmentID string, userId int64) error {
-	existingOrder, err := os.orderRepository.GetOrderByConsignmentID(consignmentID)
+func (os orderService) DeleteOrder(ctx context.Context, consignmentID string, userId int64) error {
+	ctx, span := tracer.Start(ctx, "orderService.DeleteOrder")
+	defer span.End()
+
+	existingOrder, err := os.orderRepository.GetOrderByConsignmentID(ctx, consignmentID)
 	if err != nil {
 		return err
 	}
 
 	if existingOrder.UserID != userId {
-		return fmt.Errorf("unauthorized")
+		return errOrderForbidden
 	}
 
-	return os.orderRepository.DeleteOrder(existingOrder.ID)
+	return os.orderRepository.DeleteOrder(ctx, existingOrder.ID)
 }
 
 func generateConsignmentID() string {
@@ -188,65 +219,47 @@ func generateConsignmentID() string {
 	return fmt.Sprintf("CON%d", timestamp)
 }
 
-func (os orderService) calculateDeliveryFee(order types.OrderCreateRequest) float64 {
-	baseFee := 60.0
-
-	city, err := os.cityService.GetCityByID(order.RecipientCity)
-	if err != nil {
-		return baseFee
-	}
-
-	baseFee = city.BaseDeliveryFee
-
-	// Add weight-based fee
-	if order.ItemWeight > 1.0 {
-		baseFee += (order.ItemWeight - 1.0) * 10.0
-	}
-
-	return baseFee
-}
-
-func (os orderService) calculateDeliveryFeeFromOrder(order model.Order) float64 {
-	baseFee := 60.0
-
-	city, err := os.cityService.GetCityByID(order.RecipientCity)
-	if err != nil {
-		return baseFee
+// priceOrder sets the fees of order from the base delivery fee of its
+// recipient city, falling back to the default fee when the city cannot be
+// looked up
+func (os orderService) priceOrder(ctx context.Context, order *model.Order) {
+	baseFee := pricing.DefaultBaseDeliveryFee
+	if city, err := os.cityService.GetCityByID(ctx, order.RecipientCity); err == nil {
+		baseFee = city.BaseDeliveryFee
 	}
 
-	baseFee = city.BaseDeliveryFee
-
-	// Add weight-based fee
-	if order.ItemWeight > 1.0 {
-		baseFee += (order.ItemWeight - 1.0) * 10.0
-	}
-
-	return baseFee
-}
-
-func calculateCodFee(orderAmount float64) float64 {
-	codFeePercentage := 0.01 // 1%
-	return orderAmount * codFeePercentage
+	pricing.Apply(order, baseFee)
 }
 
 func (os orderService) mapOrderToResponse(order model.Order) types.OrderResponse {
 	return types.OrderResponse{
-		ConsignmentID:    order.ConsignmentID,
-		OrderCreatedAt:   order.CreatedAt,
-		OrderDescription: order.ItemDescription,
-		MerchantOrderID:  order.MerchantOrderID,
-		RecipientName:    order.RecipientName,
-		RecipientAddress: order.RecipientAddress,
-		RecipientPhone:   order.RecipientPhone,
-		OrderAmount:      order.OrderAmount,
-		TotalFee:         order.TotalFee,
-		Instruction:      order.SpecialInstruction,
-		CodFee:           order.CodFee,
-		PromoDiscount:    order.PromoDiscount,
-		Discount:         order.Discount,
-		DeliveryFee:      order.DeliveryFee,
-		OrderStatus:      order.OrderStatus,
-		OrderType:        order.OrderType,
-		ItemType:         order.ItemType,
+		ConsignmentID:      order.ConsignmentID,
+		OrderCreatedAt:     order.CreatedAt,
+		OrderDescription:   order.ItemDescription,
+		MerchantOrderID:    order.MerchantOrderID,
+		RecipientName:      order.RecipientName,
+		RecipientAddress:   order.RecipientAddress,
+		RecipientPhone:     order.RecipientPhone,
+		RecipientCity:      order.RecipientCity,
+		RecipientZone:      order.RecipientZone,
+		RecipientArea:      order.RecipientArea,
+		OrderAmount:        order.OrderAmount,
+		TotalFee:           order.TotalFee,
+		Instruction:        order.SpecialInstruction,
+		CodFee:             order.CodFee,
+		PromoDiscount:      order.PromoDiscount,
+		Discount:           order.Discount,
+		DeliveryFee:        order.DeliveryFee,
+		OrderStatus:        order.OrderStatus,
+		OrderType:          order.OrderType,
+		Version:            order.Version,
+		ItemType:           order.ItemType,
+		CancellationReason: order.CancellationReason,
+		CancellationNote:   order.CancellationNote,
+		CancellationFee:    order.CancellationFee,
+		CancelledAt:        order.CancelledAt,
+		ItemQuantity:       order.ItemQuantity,
+		ItemWeight:         order.ItemWeight,
+		DeliveryType:       order.DeliveryTypeID,
 	}
 }
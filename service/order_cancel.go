@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"oms/apperror"
+	"oms/consts"
+	"oms/i18n"
+	"oms/types"
+	"oms/validation"
+	"time"
+)
+
+// orderCancellationFeeDue lists the statuses an order can be cancelled from,
+// and whether cancelling it then costs the cancellation fee: once the
+// parcel is picked up it has to be brought back. An order out for delivery
+// is with the rider and cannot be cancelled, nor can a finished one.
+var orderCancellationFeeDue = map[string]bool{
+	consts.OrderStatusPending:        false,
+	consts.OrderStatusConfirmed:      false,
+	consts.OrderStatusPickedUp:       true,
+	consts.OrderStatusInTransit:      true,
+	consts.OrderStatusFailedDelivery: true,
+}
+
+// CancelOrder cancels an order for one of the managed reasons. Cancelling an
+// order that is cancelled already changes nothing and returns it as it is,
+// so a retried request is safe.
+func (os orderService) CancelOrder(ctx context.Context, cancelReq types.OrderCancelRequest) (types.OrderResponse, error) {
+	ctx, span := tracer.Start(ctx, "orderService.CancelOrder")
+	defer span.End()
+
+	existingOrder, err := os.orderRepository.GetOrderByConsignmentID(ctx, cancelReq.ConsignmentID)
+	if err != nil {
+		return types.OrderResponse{}, err
+	}
+
+	if existingOrder.UserID != cancelReq.UserId {
+		return types.OrderResponse{}, errOrderForbidden
+	}
+
+	if existingOrder.OrderStatus == consts.OrderStatusCancelled {
+		return os.mapOrderToResponse(existingOrder), nil
+	}
+
+	feeDue, cancellable := orderCancellationFeeDue[existingOrder.OrderStatus]
+	if !cancellable {
+		return types.OrderResponse{}, apperror.Conflict("an order that is %s cannot be cancelled", existingOrder.OrderStatus)
+	}
+
+	lang := i18n.FromContext(ctx)
+	reason, err := os.cancellationReasonRepository.GetCancellationReasonByCode(ctx, normalizeReasonCode(cancelReq.Reason))
+	if err != nil {
+		if apperror.IsNotFound(err) {
+			return types.OrderResponse{}, apperror.Validation("Please fix the given errors").WithDetails(map[string][]string{
+				"reason": {i18n.Sprintf(lang, "The selected %s does not exist.", validation.FieldDisplayName("reason"))},
+			})
+		}
+		return types.OrderResponse{}, err
+	}
+
+	if reason.NoteRequired && cancelReq.Note == "" {
+		return types.OrderResponse{}, apperror.Validation("Please fix the given errors").WithDetails(map[string][]string{
+			"note": {i18n.Sprintf(lang, "The %s field is required.", validation.FieldDisplayName("note"))},
+		})
+	}
+
+	cancelledAt := time.Now()
+	existingOrder.OrderStatus = consts.OrderStatusCancelled
+	existingOrder.CancellationReason = reason.Code
+	existingOrder.CancellationNote = cancelReq.Note
+	existingOrder.CancelledAt = &cancelledAt
+	if feeDue {
+		existingOrder.CancellationFee = os.cancellationFee
+	}
+
+	// Refused if the order changed since it was read, such as being picked
+	// up in the meantime, so the fee always matches the status cancelled from
+	version, err := os.orderRepository.UpdateOrder(ctx, existingOrder)
+	if err != nil {
+		return types.OrderResponse{}, err
+	}
+
+	existingOrder.Version = version
+	return os.mapOrderToResponse(existingOrder), nil
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"oms/apperror"
+	"oms/consts"
+	"oms/i18n"
+	"oms/model"
+	"oms/types"
+	"oms/validation"
+)
+
+// orderEditableFields lists, by order status, the fields a merchant may still
+// change. Item details are fixed once the parcel is picked up, the address
+// once it is out for delivery, and nothing changes after the order is
+// finished.
+var orderEditableFields = map[string][]string{
+	consts.OrderStatusPending:        allOrderPatchFields,
+	consts.OrderStatusConfirmed:      allOrderPatchFields,
+	consts.OrderStatusPickedUp:       recipientOrderPatchFields,
+	consts.OrderStatusInTransit:      recipientOrderPatchFields,
+	consts.OrderStatusFailedDelivery: recipientOrderPatchFields,
+	consts.OrderStatusOutForDelivery: {"merchant_order_id", "recipient_name", "recipient_phone", "special_instruction"},
+}
+
+var (
+	allOrderPatchFields = []string{
+		"merchant_order_id", "recipient_name", "recipient_phone", "recipient_address",
+		"recipient_city", "recipient_zone", "recipient_area", "delivery_type", "item_type",
+		"item_quantity", "item_weight", "order_amount", "item_description", "special_instruction",
+	}
+	recipientOrderPatchFields = []string{
+		"merchant_order_id", "recipient_name", "recipient_phone", "recipient_address",
+		"recipient_city", "recipient_zone", "recipient_area", "order_amount", "special_instruction",
+	}
+)
+
+// orderPricingFields are the fields the fees are worked out from
+var orderPricingFields = []string{"recipient_city", "item_weight", "order_amount"}
+
+func (os orderService) PatchOrder(ctx context.Context, patchReq types.OrderPatchRequest) (types.OrderResponse, error) {
+	ctx, span := tracer.Start(ctx, "orderService.PatchOrder")
+	defer span.End()
+
+	existingOrder, err := os.orderRepository.GetOrderByConsignmentID(ctx, patchReq.ConsignmentID)
+	if err != nil {
+		return types.OrderResponse{}, err
+	}
+
+	if existingOrder.UserID != patchReq.UserId {
+		return types.OrderResponse{}, errOrderForbidden
+	}
+
+	lang := i18n.FromContext(ctx)
+	current := orderPatchOf(existingOrder)
+
+	patched, err := mergeOrderPatch(current, patchReq.Patch, lang)
+	if err != nil {
+		return types.OrderResponse{}, err
+	}
+
+	if err := validation.Struct(patched); err != nil {
+		return types.OrderResponse{}, apperror.Validation("Please fix the given errors").WithDetails(validation.FieldErrors(err, lang))
+	}
+
+	changed := changedOrderFields(current, patched)
+	if len(changed) == 0 {
+		// Nothing to write, but the client must still have seen this version
+		if patchReq.Version != existingOrder.Version {
+			return types.OrderResponse{}, apperror.Conflict("%s was changed by someone else, fetch it again and retry", i18n.Text("order"))
+		}
+		return os.mapOrderToResponse(existingOrder), nil
+	}
+
+	if err := checkOrderEditable(existingOrder.OrderStatus, changed, lang); err != nil {
+		return types.OrderResponse{}, err
+	}
+
+	if changed["recipient_city"] || changed["recipient_zone"] {
+		if err := os.checkOrderDestination(ctx, patched, lang); err != nil {
+			return types.OrderResponse{}, err
+		}
+	}
+
+	applyOrderPatch(&existingOrder, patched)
+
+	// Fees are worked out from scratch, so they follow the new values
+	// without compounding earlier fees
+	for _, field := range orderPricingFields {
+		if changed[field] {
+			os.priceOrder(ctx, &existingOrder)
+			break
+		}
+	}
+
+	// The change applies only if nobody changed the order since the client read it
+	existingOrder.Version = patchReq.Version
+	version, err := os.orderRepository.UpdateOrder(ctx, existingOrder)
+	if err != nil {
+		return types.OrderResponse{}, err
+	}
+
+	existingOrder.Version = version
+	return os.mapOrderToResponse(existingOrder), nil
+}
+
+// mergeOrderPatch applies patch to current as a JSON Merge Patch (RFC 7386):
+// each member replaces the field of the same name and null clears it, which
+// leaves required fields empty for validation to report. Members that are
+// not fields of an order, or whose value does not fit the field, are
+// reported per field.
+func mergeOrderPatch(current types.OrderPatch, patch map[string]json.RawMessage, lang string) (types.OrderPatch, error) {
+	target, err := orderPatchFields(current)
+	if err != nil {
+		return types.OrderPatch{}, err
+	}
+
+	fieldErrs := make(map[string][]string)
+	for field, value := range patch {
+		if _, known := target[field]; !known {
+			fieldErrs[field] = append(fieldErrs[field], i18n.Sprintf(lang, "The %s field cannot be changed.", validation.FieldDisplayName(field)))
+			continue
+		}
+
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			delete(target, field)
+			continue
+		}
+
+		// Decode each member on its own so a bad value is blamed on its field
+		if _, err := decodeOrderPatch(map[string]json.RawMessage{field: value}); err != nil {
+			message := validation.InvalidValueMessage(field, lang)
+			if messages := validation.FieldErrors(err, lang)[field]; len(messages) > 0 {
+				message = messages[0]
+			}
+			fieldErrs[field] = append(fieldErrs[field], message)
+			continue
+		}
+
+		target[field] = value
+	}
+
+	if len(fieldErrs) > 0 {
+		return types.OrderPatch{}, apperror.Validation("Please fix the given errors").WithDetails(fieldErrs)
+	}
+
+	return decodeOrderPatch(target)
+}
+
+// changedOrderFields names the fields whose value differs between before and
+// after, by their JSON name
+func changedOrderFields(before, after types.OrderPatch) map[string]bool {
+	beforeFields, _ := orderPatchFields(before)
+	afterFields, _ := orderPatchFields(after)
+
+	changed := make(map[string]bool)
+	for field, value := range afterFields {
+		if !bytes.Equal(value, beforeFields[field]) {
+			changed[field] = true
+		}
+	}
+	return changed
+}
+
+// checkOrderEditable refuses changes to fields an order in status no longer
+// accepts
+func checkOrderEditable(status string, changed map[string]bool, lang string) error {
+	editable := make(map[string]bool)
+	for _, field := range orderEditableFields[status] {
+		editable[field] = true
+	}
+
+	fieldErrs := make(map[string][]string)
+	for field := range changed {
+		if !editable[field] {
+			fieldErrs[field] = []string{i18n.Sprintf(lang, "The %s cannot be changed once the order is %s.", validation.FieldDisplayName(field), status)}
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return apperror.Conflict("order is %s and these fields can no longer be changed", status).WithDetails(fieldErrs)
+	}
+
+	return nil
+}
+
+// checkOrderDestination makes sure the recipient city exists and the zone is
+// one of its zones
+func (os orderService) checkOrderDestination(ctx context.Context, patched types.OrderPatch, lang string) error {
+	if _, err := os.cityService.GetCityByID(ctx, patched.RecipientCity); err != nil {
+		if apperror.IsNotFound(err) {
+			return apperror.Validation("Please fix the given errors").WithDetails(map[string][]string{
+				"recipient_city": {i18n.Sprintf(lang, "The selected %s does not exist.", validation.FieldDisplayName("recipient_city"))},
+			})
+		}
+		return err
+	}
+
+	zone, err := os.zoneService.GetZoneByID(ctx, patched.RecipientZone)
+	if err != nil && !apperror.IsNotFound(err) {
+		return err
+	}
+
+	if err != nil || zone.CityID != patched.RecipientCity {
+		return apperror.Validation("Please fix the given errors").WithDetails(map[string][]string{
+			"recipient_zone": {i18n.Sprintf(lang, "The selected %s is not in the recipient city.", validation.FieldDisplayName("recipient_zone"))},
+		})
+	}
+
+	return nil
+}
+
+// orderPatchOf returns the current values of the fields a patch may change
+func orderPatchOf(order model.Order) types.OrderPatch {
+	return types.OrderPatch{
+		MerchantOrderID:    order.MerchantOrderID,
+		RecipientName:      order.RecipientName,
+		RecipientPhone:     order.RecipientPhone,
+		RecipientAddress:   order.RecipientAddress,
+		RecipientCity:      order.RecipientCity,
+		RecipientZone:      order.RecipientZone,
+		RecipientArea:      order.RecipientArea,
+		DeliveryType:       order.DeliveryTypeID,
+		ItemType:           order.ItemType,
+		ItemQuantity:       order.ItemQuantity,
+		ItemWeight:         order.ItemWeight,
+		OrderAmount:        order.OrderAmount,
+		ItemDescription:    order.ItemDescription,
+		SpecialInstruction: order.SpecialInstruction,
+	}
+}
+
+// applyOrderPatch copies the patched fields onto order
+func applyOrderPatch(order *model.Order, patched types.OrderPatch) {
+	order.MerchantOrderID = patched.MerchantOrderID
+	order.RecipientName = patched.RecipientName
+	order.RecipientPhone = patched.RecipientPhone
+	order.RecipientAddress = patched.RecipientAddress
+	order.RecipientCity = patched.RecipientCity
+	order.RecipientZone = patched.RecipientZone
+	order.RecipientArea = patched.RecipientArea
+	order.DeliveryTypeID = patched.DeliveryType
+	order.ItemType = patched.ItemType
+	order.ItemQuantity = patched.ItemQuantity
+	order.ItemWeight = patched.ItemWeight
+	order.OrderAmount = patched.OrderAmount
+	order.ItemDescription = patched.ItemDescription
+	order.SpecialInstruction = patched.SpecialInstruction
+}
+
+// orderPatchFields returns the fields of patch as JSON values keyed by name
+func orderPatchFields(patch types.OrderPatch) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// decodeOrderPatch reads fields keyed by JSON name into an OrderPatch
+func decodeOrderPatch(fields map[string]json.RawMessage) (types.OrderPatch, error) {
+	var patch types.OrderPatch
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return patch, err
+	}
+
+	err = json.Unmarshal(data, &patch)
+	return patch, err
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"oms/apperror"
+	"oms/i18n"
+	"oms/model"
+	"oms/notification"
+	"oms/types"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ForgotPassword sends a reset link to the account's email. It succeeds for
+// unknown emails too, so the response does not reveal which emails have
+// accounts.
+func (as authService) ForgotPassword(ctx context.Context, request types.ForgotPasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "authService.ForgotPassword")
+	defer span.End()
+
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	user, err := as.userRepository.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+
+	// Only the latest link works
+	if err := as.passwordResetTokenRepository.InvalidateUserTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to issue reset token: %w", err)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return fmt.Errorf("failed to issue reset token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(as.config.PasswordResetTokenTTL)
+	err = as.passwordResetTokenRepository.CreatePasswordResetToken(ctx, model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to issue reset token: %w", err)
+	}
+
+	link, err := tokenLink(as.config.PasswordResetURL, token)
+	if err != nil {
+		return fmt.Errorf("failed to issue reset token: %w", err)
+	}
+
+	lang := userLanguage(ctx, user)
+	err = as.notifier.Notify(ctx, notification.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, "Reset your OMS password"),
+		Body: i18n.Sprintf(lang, "Use this link to choose a new password. It works once and expires at %s.\n\n%s\n\n"+
+			"If you did not ask to reset your password, you can ignore this message.",
+			expiresAt.UTC().Format(time.RFC1123), link),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send reset link: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token and signs the user
+// out everywhere
+func (as authService) ResetPassword(ctx context.Context, request types.ResetPasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "authService.ResetPassword")
+	defer span.End()
+
+	userID, err := as.passwordResetTokenRepository.ConsumePasswordResetToken(ctx, hashToken(request.Token))
+	if err != nil {
+		return err
+	}
+
+	return as.setPassword(ctx, userID, request.NewPassword)
+}
+
+// ChangePassword sets a new password after checking the current one and
+// signs the user out everywhere
+func (as authService) ChangePassword(ctx context.Context, userID int64, request types.ChangePasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "authService.ChangePassword")
+	defer span.End()
+
+	user, err := as.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.CurrentPassword))
+	if err != nil {
+		return apperror.Unauthorized("current password is incorrect")
+	}
+
+	if request.NewPassword == request.CurrentPassword {
+		return apperror.Validation("new password must differ from the current password")
+	}
+
+	return as.setPassword(ctx, userID, request.NewPassword)
+}
+
+// setPassword stores the new password, then revokes outstanding reset
+// tokens and every session so a stolen token or session stops working
+func (as authService) setPassword(ctx context.Context, userID int64, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := as.userRepository.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	if err := as.passwordResetTokenRepository.InvalidateUserTokens(ctx, userID); err != nil {
+		log.Printf("Failed to invalidate reset tokens for user %d: %v", userID, err)
+	}
+
+	if err := as.userSessionService.InvalidateUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("password changed but sessions could not be revoked: %w", err)
+	}
+
+	return nil
+}
+
+// newToken returns a random token for a link sent to the user. Only its
+// hash is stored.
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenLink adds the token to a configured page URL, the password reset page
+// or the email verification page
+func tokenLink(base, token string) (string, error) {
+	link, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
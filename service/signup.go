@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"oms/apperror"
+	"oms/consts"
+	"oms/i18n"
+	"oms/model"
+	"oms/notification"
+	"oms/types"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Signup creates an unverified merchant account and sends a verification
+// link to its email. The account cannot log in until the link is used.
+func (as authService) Signup(ctx context.Context, request types.SignupRequest) error {
+	ctx, span := tracer.Start(ctx, "authService.Signup")
+	defer span.End()
+
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	if email == "" {
+		return apperror.Validation("email cannot be empty")
+	}
+
+	existing, err := as.userRepository.GetUserByEmail(ctx, email)
+	if err == nil && existing.ID != 0 {
+		return apperror.Conflict("user with email '%s' already exists", email)
+	}
+	if err != nil && !apperror.IsNotFound(err) {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := as.userRepository.CreateUser(ctx, model.User{
+		Email:        email,
+		PasswordHash: string(hashedPassword),
+		Role:         consts.RoleMerchant,
+	})
+	if err != nil {
+		return err
+	}
+
+	return as.sendVerification(ctx, user)
+}
+
+// VerifyEmail marks the email of the token's user as verified
+func (as authService) VerifyEmail(ctx context.Context, request types.VerifyEmailRequest) error {
+	ctx, span := tracer.Start(ctx, "authService.VerifyEmail")
+	defer span.End()
+
+	userID, err := as.emailVerificationTokenRepository.ConsumeEmailVerificationToken(ctx, hashToken(request.Token))
+	if err != nil {
+		return err
+	}
+
+	return as.userRepository.MarkEmailVerified(ctx, userID)
+}
+
+// ResendVerification sends a new verification link to an unverified
+// account. It succeeds for unknown and verified emails too, so the response
+// does not reveal which emails have accounts.
+func (as authService) ResendVerification(ctx context.Context, request types.ResendVerificationRequest) error {
+	ctx, span := tracer.Start(ctx, "authService.ResendVerification")
+	defer span.End()
+
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	user, err := as.userRepository.GetUserByEmail(ctx, email)
+	if err != nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return as.sendVerification(ctx, user)
+}
+
+// sendVerification replaces any outstanding verification link of the user
+// with a new one and sends it
+func (as authService) sendVerification(ctx context.Context, user model.User) error {
+	if err := as.emailVerificationTokenRepository.InvalidateUserTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to issue verification token: %w", err)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return fmt.Errorf("failed to issue verification token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(as.config.EmailVerificationTokenTTL)
+	err = as.emailVerificationTokenRepository.CreateEmailVerificationToken(ctx, model.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to issue verification token: %w", err)
+	}
+
+	link, err := tokenLink(as.config.EmailVerificationURL, token)
+	if err != nil {
+		return fmt.Errorf("failed to issue verification token: %w", err)
+	}
+
+	lang := userLanguage(ctx, user)
+	err = as.notifier.Notify(ctx, notification.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, "Verify your OMS email address"),
+		Body: i18n.Sprintf(lang, "Use this link to verify your email address and activate your account. It expires at %s.\n\n%s\n\n"+
+			"If you did not sign up, you can ignore this message.",
+			expiresAt.UTC().Format(time.RFC1123), link),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification link: %w", err)
+	}
+
+	return nil
+}
@@ -1,7 +1,8 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 	"oms/types"
@@ -15,10 +16,16 @@ func NewStoreService(storeRepository domain.StoreRepository) domain.StoreService
 	return &storeService{storeRepository: storeRepository}
 }
 
-func (ss storeService) CreateStore(store types.StoreCreateRequest) error {
-	existing, err := ss.storeRepository.GetStoreByName(store.Name)
+func (ss storeService) CreateStore(ctx context.Context, store types.StoreCreateRequest) error {
+	ctx, span := tracer.Start(ctx, "storeService.CreateStore")
+	defer span.End()
+
+	existing, err := ss.storeRepository.GetStoreByName(ctx, store.Name)
 	if err == nil && existing.ID != 0 {
-		return fmt.Errorf("store with name '%s' already exists", store.Name)
+		return apperror.Conflict("store with name '%s' already exists", store.Name)
+	}
+	if err != nil && !apperror.IsNotFound(err) {
+		return err
 	}
 
 	newStore := model.Store{
@@ -27,7 +34,7 @@ func (ss storeService) CreateStore(store types.StoreCreateRequest) error {
 		Address:      store.Address,
 	}
 
-	err = ss.storeRepository.CreateStore(newStore)
+	err = ss.storeRepository.CreateStore(ctx, newStore)
 	if err != nil {
 		return err
 	}
@@ -35,8 +42,11 @@ func (ss storeService) CreateStore(store types.StoreCreateRequest) error {
 	return nil
 }
 
-func (ss storeService) GetStoreByID(id int64) (types.StoreResponse, error) {
-	existingStore, err := ss.storeRepository.GetStoreByID(id)
+func (ss storeService) GetStoreByID(ctx context.Context, id int64) (types.StoreResponse, error) {
+	ctx, span := tracer.Start(ctx, "storeService.GetStoreByID")
+	defer span.End()
+
+	existingStore, err := ss.storeRepository.GetStoreByID(ctx, id)
 	if err != nil {
 		return types.StoreResponse{}, err
 	}
@@ -47,11 +57,15 @@ func (ss storeService) GetStoreByID(id int64) (types.StoreResponse, error) {
 		ContactPhone: existingStore.ContactPhone,
 		Address:      existingStore.Address,
 		UpdatedAt:    existingStore.UpdatedAt,
+		Version:      existingStore.Version,
 	}, nil
 }
 
-func (ss storeService) GetAllStores(limit, offset int) ([]types.StoreResponse, error) {
-	existingStores, err := ss.storeRepository.GetAllStores(limit, offset)
+func (ss storeService) GetAllStores(ctx context.Context, limit, offset int) ([]types.StoreResponse, error) {
+	ctx, span := tracer.Start(ctx, "storeService.GetAllStores")
+	defer span.End()
+
+	existingStores, err := ss.storeRepository.GetAllStores(ctx, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -65,22 +79,29 @@ func (ss storeService) GetAllStores(limit, offset int) ([]types.StoreResponse, e
 			ContactPhone: existingStore.ContactPhone,
 			Address:      existingStore.Address,
 			UpdatedAt:    existingStore.UpdatedAt,
+			Version:      existingStore.Version,
 		})
 	}
 
 	return result, nil
 }
 
-func (ss storeService) UpdateStore(store types.StoreUpdateRequest) error {
-	existingStore, err := ss.storeRepository.GetStoreByID(store.ID)
+func (ss storeService) UpdateStore(ctx context.Context, store types.StoreUpdateRequest) (int64, error) {
+	ctx, span := tracer.Start(ctx, "storeService.UpdateStore")
+	defer span.End()
+
+	existingStore, err := ss.storeRepository.GetStoreByID(ctx, store.ID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if store.Name != existingStore.Name {
-		existing, err := ss.storeRepository.GetStoreByName(store.Name)
+		existing, err := ss.storeRepository.GetStoreByName(ctx, store.Name)
 		if err == nil && existing.ID != 0 {
-			return fmt.Errorf("store with name '%s' already exists", store.Name)
+			return 0, apperror.Conflict("store with name '%s' already exists", store.Name)
+		}
+		if err != nil && !apperror.IsNotFound(err) {
+			return 0, err
 		}
 
 		existingStore.Name = store.Name
@@ -94,19 +115,18 @@ func (ss storeService) UpdateStore(store types.StoreUpdateRequest) error {
 		existingStore.Address = store.Address
 	}
 
-	err = ss.storeRepository.UpdateStore(existingStore)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	// The change applies only if nobody changed the store since the client read it
+	existingStore.Version = store.Version
+	return ss.storeRepository.UpdateStore(ctx, existingStore)
 }
 
-func (ss storeService) DeleteStore(id int64) error {
-	existingStore, err := ss.storeRepository.GetStoreByID(id)
-	if err != nil || existingStore.ID == 0 {
-		return fmt.Errorf("store does not exist")
+func (ss storeService) DeleteStore(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "storeService.DeleteStore")
+	defer span.End()
+
+	if _, err := ss.storeRepository.GetStoreByID(ctx, id); err != nil {
+		return err
 	}
 
-	return ss.storeRepository.DeleteStore(id)
+	return ss.storeRepository.DeleteStore(ctx, id)
 }
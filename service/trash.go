@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"log"
+	"oms/config"
+	"oms/domain"
+	"oms/model"
+	"oms/types"
+	"time"
+)
+
+type trashService struct {
+	trashRepository domain.TrashRepository
+	retention       time.Duration
+}
+
+func NewTrashService(trashRepository domain.TrashRepository, config config.Config) domain.TrashService {
+	return &trashService{
+		trashRepository: trashRepository,
+		retention:       config.TrashRetention,
+	}
+}
+
+func (ts trashService) ListTrash(ctx context.Context, kind string, limit, offset int) ([]types.TrashedRecordResponse, error) {
+	ctx, span := tracer.Start(ctx, "trashService.ListTrash")
+	defer span.End()
+
+	records, err := ts.trashRepository.ListDeleted(ctx, kind, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]types.TrashedRecordResponse, 0, len(records))
+	for _, record := range records {
+		responses = append(responses, mapTrashedRecordToResponse(record))
+	}
+
+	return responses, nil
+}
+
+func (ts trashService) RestoreFromTrash(ctx context.Context, kind string, id int64) error {
+	ctx, span := tracer.Start(ctx, "trashService.RestoreFromTrash")
+	defer span.End()
+
+	return ts.trashRepository.Restore(ctx, kind, id)
+}
+
+// PurgeExpired permanently deletes records that have been in the trash for
+// longer than the retention period
+func (ts trashService) PurgeExpired(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "trashService.PurgeExpired")
+	defer span.End()
+
+	purged, err := ts.trashRepository.PurgeDeleted(ctx, time.Now().Add(-ts.retention))
+	for kind, count := range purged {
+		if count > 0 {
+			log.Printf("Purged %d %s deleted more than %s ago", count, kind, ts.retention)
+		}
+	}
+
+	return err
+}
+
+// RunTrashPurge purges expired records from the trash now and then every
+// interval until ctx is cancelled. Running it on every instance is safe, a
+// record is only purged once.
+func RunTrashPurge(ctx context.Context, trashService domain.TrashService, interval time.Duration) {
+	purge := func() {
+		if err := trashService.PurgeExpired(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Trash purge failed: %v", err)
+		}
+	}
+
+	purge()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purge()
+		}
+	}
+}
+
+func mapTrashedRecordToResponse(record model.TrashedRecord) types.TrashedRecordResponse {
+	return types.TrashedRecordResponse{
+		ID:        record.ID,
+		Label:     record.Label,
+		DeletedAt: record.DeletedAt,
+	}
+}
@@ -1,11 +1,15 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"oms/apperror"
+	"oms/consts"
 	"oms/domain"
 	"oms/model"
 	"oms/types"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -18,17 +22,23 @@ func NewUserService(userRepository domain.UserRepository) domain.UserService {
 	return &userService{userRepository: userRepository}
 }
 
-func (us userService) CreateUser(user types.UserCreateRequest) error {
+func (us userService) CreateUser(ctx context.Context, user types.UserCreateRequest) error {
+	ctx, span := tracer.Start(ctx, "userService.CreateUser")
+	defer span.End()
+
 	// Normalize email (trim spaces and convert to lowercase)
 	normalizedEmail := strings.ToLower(strings.TrimSpace(user.Email))
 	if normalizedEmail == "" {
-		return fmt.Errorf("email cannot be empty")
+		return apperror.Validation("email cannot be empty")
 	}
 
 	// Check if user with same email already exists
-	existing, err := us.userRepository.GetUserByEmail(normalizedEmail)
+	existing, err := us.userRepository.GetUserByEmail(ctx, normalizedEmail)
 	if err == nil && existing.ID != 0 {
-		return fmt.Errorf("user with email '%s' already exists", normalizedEmail)
+		return apperror.Conflict("user with email '%s' already exists", normalizedEmail)
+	}
+	if err != nil && !apperror.IsNotFound(err) {
+		return err
 	}
 
 	// Hash password
@@ -37,12 +47,21 @@ func (us userService) CreateUser(user types.UserCreateRequest) error {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	role := user.Role
+	if role == "" {
+		role = consts.RoleMerchant
+	}
+
+	// Users are created here by admins, who vouch for the email
+	verifiedAt := time.Now()
 	newUser := model.User{
-		Email:        normalizedEmail,
-		PasswordHash: string(hashedPassword),
+		Email:           normalizedEmail,
+		PasswordHash:    string(hashedPassword),
+		Role:            role,
+		EmailVerifiedAt: &verifiedAt,
 	}
 
-	err = us.userRepository.CreateUser(newUser)
+	_, err = us.userRepository.CreateUser(ctx, newUser)
 	if err != nil {
 		return err
 	}
@@ -50,22 +69,31 @@ func (us userService) CreateUser(user types.UserCreateRequest) error {
 	return nil
 }
 
-func (us userService) GetUserByID(id int64) (types.UserResponse, error) {
-	existingUser, err := us.userRepository.GetUserByID(id)
+func (us userService) GetUserByID(ctx context.Context, id int64) (types.UserResponse, error) {
+	ctx, span := tracer.Start(ctx, "userService.GetUserByID")
+	defer span.End()
+
+	existingUser, err := us.userRepository.GetUserByID(ctx, id)
 	if err != nil {
 		return types.UserResponse{}, err
 	}
 
 	return types.UserResponse{
-		ID:        existingUser.ID,
-		Email:     existingUser.Email,
-		CreatedAt: existingUser.CreatedAt,
-		UpdatedAt: existingUser.UpdatedAt,
+		ID:            existingUser.ID,
+		Email:         existingUser.Email,
+		Role:          existingUser.Role,
+		EmailVerified: existingUser.EmailVerifiedAt != nil,
+		Language:      existingUser.Language,
+		CreatedAt:     existingUser.CreatedAt,
+		UpdatedAt:     existingUser.UpdatedAt,
 	}, nil
 }
 
-func (us userService) GetAllUsers(limit, offset int) ([]types.UserResponse, error) {
-	existingUsers, err := us.userRepository.GetAllUsers(limit, offset)
+func (us userService) GetAllUsers(ctx context.Context, limit, offset int) ([]types.UserResponse, error) {
+	ctx, span := tracer.Start(ctx, "userService.GetAllUsers")
+	defer span.End()
+
+	existingUsers, err := us.userRepository.GetAllUsers(ctx, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -74,41 +102,50 @@ func (us userService) GetAllUsers(limit, offset int) ([]types.UserResponse, erro
 
 	for _, existingUser := range existingUsers {
 		result = append(result, types.UserResponse{
-			ID:        existingUser.ID,
-			Email:     existingUser.Email,
-			CreatedAt: existingUser.CreatedAt,
-			UpdatedAt: existingUser.UpdatedAt,
+			ID:            existingUser.ID,
+			Email:         existingUser.Email,
+			Role:          existingUser.Role,
+			EmailVerified: existingUser.EmailVerifiedAt != nil,
+			Language:      existingUser.Language,
+			CreatedAt:     existingUser.CreatedAt,
+			UpdatedAt:     existingUser.UpdatedAt,
 		})
 	}
 
 	return result, nil
 }
 
-func (us userService) UpdateUserEmail(user types.UserUpdateRequest) error {
-	existingUser, err := us.userRepository.GetUserByID(user.ID)
+func (us userService) UpdateUserEmail(ctx context.Context, user types.UserUpdateRequest) error {
+	ctx, span := tracer.Start(ctx, "userService.UpdateUserEmail")
+	defer span.End()
+
+	existingUser, err := us.userRepository.GetUserByID(ctx, user.ID)
 	if err != nil {
 		return err
 	}
 
 	normalizedEmail := strings.ToLower(strings.TrimSpace(user.Email))
 	if normalizedEmail == "" {
-		return fmt.Errorf("email cannot be empty")
+		return apperror.Validation("email cannot be empty")
 	}
 
 	if normalizedEmail != existingUser.Email {
-		existing, err := us.userRepository.GetUserByEmail(normalizedEmail)
+		existing, err := us.userRepository.GetUserByEmail(ctx, normalizedEmail)
 		if err == nil && existing.ID != 0 && existing.ID != existingUser.ID {
-			return fmt.Errorf("user with email '%s' already exists", normalizedEmail)
+			return apperror.Conflict("user with email '%s' already exists", normalizedEmail)
+		}
+		if err != nil && !apperror.IsNotFound(err) {
+			return err
 		}
 		existingUser.Email = normalizedEmail
 	}
 
-	isVerified := us.VerifyUserCredentials(user.Email, user.Password)
+	isVerified := us.VerifyUserCredentials(ctx, user.Email, user.Password)
 	if !isVerified {
-		return fmt.Errorf("user with email '%s' has not been verified", user.Email)
+		return apperror.Validation("user with email '%s' has not been verified", user.Email)
 	}
 
-	err = us.userRepository.UpdateUserEmail(existingUser)
+	err = us.userRepository.UpdateUserEmail(ctx, existingUser)
 	if err != nil {
 		return err
 	}
@@ -116,33 +153,53 @@ func (us userService) UpdateUserEmail(user types.UserUpdateRequest) error {
 	return nil
 }
 
-func (us userService) DeleteUser(id int64) error {
-	existingUser, err := us.userRepository.GetUserByID(id)
-	if err != nil || existingUser.ID == 0 {
-		return fmt.Errorf("user does not exist")
+// UpdateLanguage saves the language the user wants responses and
+// notifications in
+func (us userService) UpdateLanguage(ctx context.Context, userID int64, request types.UserLanguageRequest) error {
+	ctx, span := tracer.Start(ctx, "userService.UpdateLanguage")
+	defer span.End()
+
+	return us.userRepository.UpdateLanguage(ctx, userID, request.Language)
+}
+
+func (us userService) DeleteUser(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "userService.DeleteUser")
+	defer span.End()
+
+	if _, err := us.userRepository.GetUserByID(ctx, id); err != nil {
+		return err
 	}
 
-	return us.userRepository.DeleteUser(id)
+	return us.userRepository.DeleteUser(ctx, id)
 }
 
-func (us userService) GetUserByEmail(email string) (types.UserResponse, error) {
+func (us userService) GetUserByEmail(ctx context.Context, email string) (types.UserResponse, error) {
+	ctx, span := tracer.Start(ctx, "userService.GetUserByEmail")
+	defer span.End()
+
 	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
-	existingUser, err := us.userRepository.GetUserByEmail(normalizedEmail)
+	existingUser, err := us.userRepository.GetUserByEmail(ctx, normalizedEmail)
 	if err != nil {
 		return types.UserResponse{}, err
 	}
 
 	return types.UserResponse{
-		ID:        existingUser.ID,
-		Email:     existingUser.Email,
-		CreatedAt: existingUser.CreatedAt,
-		UpdatedAt: existingUser.UpdatedAt,
+		ID:            existingUser.ID,
+		Email:         existingUser.Email,
+		Role:          existingUser.Role,
+		EmailVerified: existingUser.EmailVerifiedAt != nil,
+		Language:      existingUser.Language,
+		CreatedAt:     existingUser.CreatedAt,
+		UpdatedAt:     existingUser.UpdatedAt,
 	}, nil
 }
 
-func (us userService) VerifyUserCredentials(email, password string) bool {
+func (us userService) VerifyUserCredentials(ctx context.Context, email, password string) bool {
+	ctx, span := tracer.Start(ctx, "userService.VerifyUserCredentials")
+	defer span.End()
+
 	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
-	existingUser, err := us.userRepository.GetUserByEmail(normalizedEmail)
+	existingUser, err := us.userRepository.GetUserByEmail(ctx, normalizedEmail)
 	if err != nil {
 		return false
 	}
@@ -1,7 +1,8 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"oms/apperror"
 	"oms/config"
 	"oms/domain"
 	"oms/model"
@@ -25,13 +26,18 @@ func NewUserSessionService(
 	}
 }
 
-func (uss userSessionService) CreateUserSession(userID int64) (model.UserSession, error) {
-	accessToken, err := utility.GenerateJWT(userID, uss.config.AccessTokenExpirationTime)
+// CreateUserSession starts a session. mfaVerified records that the user
+// passed a second factor, which some routes require.
+func (uss userSessionService) CreateUserSession(ctx context.Context, userID int64, mfaVerified bool) (model.UserSession, error) {
+	ctx, span := tracer.Start(ctx, "userSessionService.CreateUserSession")
+	defer span.End()
+
+	accessToken, err := utility.GenerateJWT(uss.config.JWTSecret, userID, uss.config.AccessTokenExpirationTime)
 	if err != nil {
 		return model.UserSession{}, err
 	}
 
-	refreshToken, err := utility.GenerateJWT(userID, uss.config.RefreshTokenExpirationTime)
+	refreshToken, err := utility.GenerateJWT(uss.config.JWTSecret, userID, uss.config.RefreshTokenExpirationTime)
 	if err != nil {
 		return model.UserSession{}, err
 	}
@@ -41,9 +47,12 @@ func (uss userSessionService) CreateUserSession(userID int64) (model.UserSession
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		ExpiresAt:    time.Now().UTC().Add(uss.config.AccessTokenExpirationTime),
+		MFAVerified:  mfaVerified,
 	}
 
-	err = uss.userSessionRepository.CreateUserSession(session)
+	// Attribute the write to the user so their first authenticated request
+	// finds the new session even if the replica has not caught up yet
+	err = uss.userSessionRepository.CreateUserSession(utility.ContextWithUserID(ctx, userID), session)
 	if err != nil {
 		return model.UserSession{}, err
 	}
@@ -51,31 +60,49 @@ func (uss userSessionService) CreateUserSession(userID int64) (model.UserSession
 	return session, nil
 }
 
-func (uss userSessionService) ValidateSession(accessToken string) (types.UserSessionResponse, error) {
-	session, err := uss.userSessionRepository.GetUserSessionByAccessToken(accessToken)
+func (uss userSessionService) ValidateSession(ctx context.Context, accessToken string) (types.UserSessionResponse, error) {
+	ctx, span := tracer.Start(ctx, "userSessionService.ValidateSession")
+	defer span.End()
+
+	session, err := uss.userSessionRepository.GetUserSessionByAccessToken(ctx, accessToken)
 	if err != nil {
 		return types.UserSessionResponse{}, err
 	}
 
 	// Check if session is expired
 	if time.Now().After(session.ExpiresAt) {
-		_ = uss.userSessionRepository.InvalidateSession(accessToken)
-		return types.UserSessionResponse{}, fmt.Errorf("session expired")
+		_ = uss.userSessionRepository.InvalidateSession(ctx, accessToken)
+		return types.UserSessionResponse{}, apperror.Unauthorized("session expired")
 	}
 
 	return types.UserSessionResponse{
-		ID:        session.ID,
-		UserID:    session.UserID,
-		ExpiresAt: session.ExpiresAt,
-		CreatedAt: session.CreatedAt,
-		UpdatedAt: session.UpdatedAt,
+		ID:          session.ID,
+		UserID:      session.UserID,
+		ExpiresAt:   session.ExpiresAt,
+		MFAVerified: session.MFAVerified,
+		Language:    session.Language,
+		CreatedAt:   session.CreatedAt,
+		UpdatedAt:   session.UpdatedAt,
 	}, nil
 }
 
-func (uss userSessionService) CleanupExpiredSessions() error {
-	return uss.userSessionRepository.DeleteExpiredSessions()
+func (uss userSessionService) CleanupExpiredSessions(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "userSessionService.CleanupExpiredSessions")
+	defer span.End()
+
+	return uss.userSessionRepository.DeleteExpiredSessions(ctx)
 }
 
-func (uss userSessionService) InvalidateSession(accessToken string) error {
-	return uss.userSessionRepository.InvalidateSession(accessToken)
+func (uss userSessionService) InvalidateSession(ctx context.Context, accessToken string) error {
+	ctx, span := tracer.Start(ctx, "userSessionService.InvalidateSession")
+	defer span.End()
+
+	return uss.userSessionRepository.InvalidateSession(ctx, accessToken)
+}
+
+func (uss userSessionService) InvalidateUserSessions(ctx context.Context, userID int64) error {
+	ctx, span := tracer.Start(ctx, "userSessionService.InvalidateUserSessions")
+	defer span.End()
+
+	return uss.userSessionRepository.InvalidateUserSessions(ctx, userID)
 }
@@ -1,7 +1,8 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"oms/apperror"
 	"oms/domain"
 	"oms/model"
 	"oms/types"
@@ -19,17 +20,26 @@ func NewZoneService(zoneRepository domain.ZoneRepository, cityRepository domain.
 	}
 }
 
-func (zs zoneService) CreateZone(zone types.ZoneCreateRequest) error {
+func (zs zoneService) CreateZone(ctx context.Context, zone types.ZoneCreateRequest) error {
+	ctx, span := tracer.Start(ctx, "zoneService.CreateZone")
+	defer span.End()
+
 	// Check if city exists
-	_, err := zs.cityRepository.GetCityByID(zone.CityID)
+	_, err := zs.cityRepository.GetCityByID(ctx, zone.CityID)
+	if apperror.IsNotFound(err) {
+		return apperror.Validation("city with ID %d does not exist", zone.CityID)
+	}
 	if err != nil {
-		return fmt.Errorf("city with ID %d does not exist", zone.CityID)
+		return err
 	}
 
 	// Check if zone with same name already exists in the same city
-	existing, err := zs.zoneRepository.GetZoneByNameAndCityID(zone.Name, zone.CityID)
+	existing, err := zs.zoneRepository.GetZoneByNameAndCityID(ctx, zone.Name, zone.CityID)
 	if err == nil && existing.ID != 0 {
-		return fmt.Errorf("zone with name '%s' already exists in this city", zone.Name)
+		return apperror.Conflict("zone with name '%s' already exists in this city", zone.Name)
+	}
+	if err != nil && !apperror.IsNotFound(err) {
+		return err
 	}
 
 	newZone := model.Zone{
@@ -37,7 +47,7 @@ func (zs zoneService) CreateZone(zone types.ZoneCreateRequest) error {
 		Name:   zone.Name,
 	}
 
-	err = zs.zoneRepository.CreateZone(newZone)
+	err = zs.zoneRepository.CreateZone(ctx, newZone)
 	if err != nil {
 		return err
 	}
@@ -45,8 +55,11 @@ func (zs zoneService) CreateZone(zone types.ZoneCreateRequest) error {
 	return nil
 }
 
-func (zs zoneService) GetZoneByID(id int64) (types.ZoneResponse, error) {
-	existingZone, err := zs.zoneRepository.GetZoneByID(id)
+func (zs zoneService) GetZoneByID(ctx context.Context, id int64) (types.ZoneResponse, error) {
+	ctx, span := tracer.Start(ctx, "zoneService.GetZoneByID")
+	defer span.End()
+
+	existingZone, err := zs.zoneRepository.GetZoneByID(ctx, id)
 	if err != nil {
 		return types.ZoneResponse{}, err
 	}
@@ -57,11 +70,15 @@ func (zs zoneService) GetZoneByID(id int64) (types.ZoneResponse, error) {
 		Name:      existingZone.Name,
 		CreatedAt: existingZone.CreatedAt,
 		UpdatedAt: existingZone.UpdatedAt,
+		Version:   existingZone.Version,
 	}, nil
 }
 
-func (zs zoneService) GetAllZones(limit, offset int) ([]types.ZoneResponse, error) {
-	existingZones, err := zs.zoneRepository.GetAllZones(limit, offset)
+func (zs zoneService) GetAllZones(ctx context.Context, limit, offset int) ([]types.ZoneResponse, error) {
+	ctx, span := tracer.Start(ctx, "zoneService.GetAllZones")
+	defer span.End()
+
+	existingZones, err := zs.zoneRepository.GetAllZones(ctx, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -75,20 +92,24 @@ func (zs zoneService) GetAllZones(limit, offset int) ([]types.ZoneResponse, erro
 			Name:      existingZone.Name,
 			CreatedAt: existingZone.CreatedAt,
 			UpdatedAt: existingZone.UpdatedAt,
+			Version:   existingZone.Version,
 		})
 	}
 
 	return result, nil
 }
 
-func (zs zoneService) GetZonesByCityID(cityID int64, limit, offset int) ([]types.ZoneResponse, error) {
+func (zs zoneService) GetZonesByCityID(ctx context.Context, cityID int64, limit, offset int) ([]types.ZoneResponse, error) {
+	ctx, span := tracer.Start(ctx, "zoneService.GetZonesByCityID")
+	defer span.End()
+
 	// Check if city exists
-	_, err := zs.cityRepository.GetCityByID(cityID)
+	_, err := zs.cityRepository.GetCityByID(ctx, cityID)
 	if err != nil {
-		return nil, fmt.Errorf("city with ID %d does not exist", cityID)
+		return nil, err
 	}
 
-	existingZones, err := zs.zoneRepository.GetZonesByCityID(cityID, limit, offset)
+	existingZones, err := zs.zoneRepository.GetZonesByCityID(ctx, cityID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -102,40 +123,46 @@ func (zs zoneService) GetZonesByCityID(cityID int64, limit, offset int) ([]types
 			Name:      existingZone.Name,
 			CreatedAt: existingZone.CreatedAt,
 			UpdatedAt: existingZone.UpdatedAt,
+			Version:   existingZone.Version,
 		})
 	}
 
 	return result, nil
 }
 
-func (zs zoneService) UpdateZone(zone types.ZoneUpdateRequest) error {
-	existingZone, err := zs.zoneRepository.GetZoneByID(zone.ID)
+func (zs zoneService) UpdateZone(ctx context.Context, zone types.ZoneUpdateRequest) (int64, error) {
+	ctx, span := tracer.Start(ctx, "zoneService.UpdateZone")
+	defer span.End()
+
+	existingZone, err := zs.zoneRepository.GetZoneByID(ctx, zone.ID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if zone.Name != existingZone.Name {
-		existing, err := zs.zoneRepository.GetZoneByNameAndCityID(zone.Name, existingZone.CityID)
+		existing, err := zs.zoneRepository.GetZoneByNameAndCityID(ctx, zone.Name, existingZone.CityID)
 		if err == nil && existing.ID != 0 && existing.ID != existingZone.ID {
-			return fmt.Errorf("zone with name '%s' already exists in this city", zone.Name)
+			return 0, apperror.Conflict("zone with name '%s' already exists in this city", zone.Name)
+		}
+		if err != nil && !apperror.IsNotFound(err) {
+			return 0, err
 		}
 
 		existingZone.Name = zone.Name
 	}
 
-	err = zs.zoneRepository.UpdateZone(existingZone)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	// The change applies only if nobody changed the zone since the client read it
+	existingZone.Version = zone.Version
+	return zs.zoneRepository.UpdateZone(ctx, existingZone)
 }
 
-func (zs zoneService) DeleteZone(id int64) error {
-	existingZone, err := zs.zoneRepository.GetZoneByID(id)
-	if err != nil || existingZone.ID == 0 {
-		return fmt.Errorf("zone does not exist")
+func (zs zoneService) DeleteZone(ctx context.Context, id int64) error {
+	ctx, span := tracer.Start(ctx, "zoneService.DeleteZone")
+	defer span.End()
+
+	if _, err := zs.zoneRepository.GetZoneByID(ctx, id); err != nil {
+		return err
 	}
 
-	return zs.zoneRepository.DeleteZone(id)
+	return zs.zoneRepository.DeleteZone(ctx, id)
 }
@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+type CancellationReasonCreateRequest struct {
+	Code         string `json:"code" validate:"required,max=50"`
+	Description  string `json:"description" validate:"required,max=255"`
+	NoteRequired bool   `json:"note_required"`
+}
+
+type CancellationReasonUpdateRequest struct {
+	ID           int64  `json:"id" validate:"required"`
+	Code         string `json:"code" validate:"required,max=50"`
+	Description  string `json:"description" validate:"required,max=255"`
+	NoteRequired bool   `json:"note_required"`
+}
+
+type CancellationReasonResponse struct {
+	ID           int64     `json:"id"`
+	Code         string    `json:"code"`
+	Description  string    `json:"description"`
+	NoteRequired bool      `json:"noteRequired"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
@@ -1,21 +1,26 @@
 package types
 
-import "time"
+import (
+	"oms/money"
+	"time"
+)
 
 type CityCreateRequest struct {
-	Name            string  `json:"name" binding:"required"`
-	BaseDeliveryFee float64 `json:"baseDeliveryFee"`
+	Name            string       `json:"name" validate:"required"`
+	BaseDeliveryFee money.Amount `json:"baseDeliveryFee" validate:"money"`
 }
 
 type CityUpdateRequest struct {
-	ID              int64   `json:"id" binding:"required"`
-	Name            string  `json:"name" binding:"required"`
-	BaseDeliveryFee float64 `json:"baseDeliveryFee"`
+	ID              int64        `json:"id" validate:"required"`
+	Name            string       `json:"name" validate:"required"`
+	BaseDeliveryFee money.Amount `json:"baseDeliveryFee" validate:"money"`
+	Version         int64        `json:"-"` // From If-Match
 }
 
 type CityResponse struct {
-	Id              int64     `json:"id,omitempty"`
-	Name            string    `json:"name"`
-	BaseDeliveryFee float64   `json:"baseDeliveryFee,omitempty"`
-	UpdatedAt       time.Time `json:"updatedAt,omitempty"`
+	Id              int64        `json:"id,omitempty"`
+	Name            string       `json:"name"`
+	BaseDeliveryFee money.Amount `json:"baseDeliveryFee,omitempty"`
+	UpdatedAt       time.Time    `json:"updatedAt,omitempty"`
+	Version         int64        `json:"version"`
 }
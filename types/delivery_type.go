@@ -3,12 +3,12 @@ package types
 import "time"
 
 type DeliveryTypeCreateRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name string `json:"name" validate:"required"`
 }
 
 type DeliveryTypeUpdateRequest struct {
-	ID   int64  `json:"id" binding:"required"`
-	Name string `json:"name" binding:"required"`
+	ID   int64  `json:"id" validate:"required"`
+	Name string `json:"name" validate:"required"`
 }
 
 type DeliveryTypeResponse struct {
@@ -0,0 +1,17 @@
+package types
+
+type LivenessResponse struct {
+	Status string `json:"status"`
+}
+
+type DependencyStatus struct {
+	Status    string         `json:"status"`
+	LatencyMs int64          `json:"latency_ms"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status string                      `json:"status"`
+	Checks map[string]DependencyStatus `json:"checks"`
+}
@@ -3,7 +3,7 @@ package types
 import "time"
 
 type ItemTypeCreateRequest struct {
-	Name string `json:"name" binding:"required" validate:"required,max=50"`
+	Name string `json:"name" validate:"required,max=50"`
 }
 
 type ItemTypeUpdateRequest struct {
@@ -0,0 +1,9 @@
+package types
+
+import "time"
+
+type TrashedRecordResponse struct {
+	ID        int64     `json:"id"`
+	Label     string    `json:"label"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
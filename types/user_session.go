@@ -3,11 +3,13 @@ package types
 import "time"
 
 type UserSessionResponse struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"userId"`
-	ExpiresAt time.Time `json:"expiresAt"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"userId"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	MFAVerified bool      `json:"mfaVerified"`
+	Language    string    `json:"language,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
 }
 
 type CreateSessionRequest struct {
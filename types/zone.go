@@ -3,14 +3,15 @@ package types
 import "time"
 
 type ZoneCreateRequest struct {
-	CityID int64  `json:"cityId" binding:"required" validate:"required"`
-	Name   string `json:"name" binding:"required" validate:"required,max=100"`
+	CityID int64  `json:"cityId" validate:"required"`
+	Name   string `json:"name" validate:"required,max=100"`
 }
 
 // ZoneUpdateRequest represents the request structure for updating an existing zone
 type ZoneUpdateRequest struct {
-	ID   int64  `json:"id" binding:"required" validate:"required"`
-	Name string `json:"name" validate:"required,max=100"`
+	ID      int64  `json:"id" validate:"required"`
+	Name    string `json:"name" validate:"required,max=100"`
+	Version int64  `json:"-"` // From If-Match
 }
 
 // ZoneResponse represents the response structure for zone data
@@ -20,6 +21,7 @@ type ZoneResponse struct {
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
 }
 
 // ZonesListResponse represents the response structure for paginated zone lists
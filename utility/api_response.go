@@ -1,6 +1,10 @@
 package utility
 
-import "github.com/gin-gonic/gin"
+import (
+	"oms/i18n"
+
+	"github.com/gin-gonic/gin"
+)
 
 type APIResponse struct {
 	Message string      `json:"message"`
@@ -10,9 +14,14 @@ type APIResponse struct {
 	Errors  interface{} `json:"errors,omitempty"`
 }
 
+// SendSuccessResponse writes data in the API envelope, with message
+// translated into the language of the request
 func SendSuccessResponse(ctx *gin.Context, code int, message string, data interface{}) {
+	lang := Language(ctx)
+	ctx.Header("Content-Language", lang)
+
 	response := APIResponse{
-		Message: message,
+		Message: i18n.T(lang, message),
 		Type:    "success",
 		Code:    code,
 		Data:    data,
@@ -21,7 +30,24 @@ func SendSuccessResponse(ctx *gin.Context, code int, message string, data interf
 	ctx.JSON(code, response)
 }
 
+// SendErrorResponse writes an error in the API envelope, or as problem
+// details when the client asks for them. errs may be an error, which is
+// reported by its message. message is translated into the language of the
+// request.
 func SendErrorResponse(ctx *gin.Context, code int, message string, errs interface{}) {
+	lang := Language(ctx)
+	ctx.Header("Content-Language", lang)
+	message = i18n.T(lang, message)
+
+	if WantsProblem(ctx) {
+		SendProblem(ctx, code, message, errs)
+		return
+	}
+
+	if err, ok := errs.(error); ok {
+		errs = []any{err.Error()}
+	}
+
 	response := APIResponse{
 		Message: message,
 		Type:    "error",
@@ -31,3 +57,8 @@ func SendErrorResponse(ctx *gin.Context, code int, message string, errs interfac
 
 	ctx.JSON(code, response)
 }
+
+// Language returns the language responses to this request are written in
+func Language(ctx *gin.Context) string {
+	return i18n.FromContext(ctx.Request.Context())
+}
@@ -0,0 +1,35 @@
+package utility
+
+import (
+	"net/http"
+	"oms/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BindJSON decodes the request body into obj and validates it. When that
+// fails it writes the error response and returns false: 422 with the
+// messages per field, or 400 when the body is not JSON at all.
+func BindJSON(ctx *gin.Context, obj any) bool {
+	return bindResult(ctx, ctx.ShouldBindJSON(obj))
+}
+
+// BindQuery decodes the query string into obj and validates it, responding
+// the same way as BindJSON when that fails
+func BindQuery(ctx *gin.Context, obj any) bool {
+	return bindResult(ctx, ctx.ShouldBindQuery(obj))
+}
+
+func bindResult(ctx *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	if fields := validation.FieldErrors(err, Language(ctx)); fields != nil {
+		SendErrorResponse(ctx, http.StatusUnprocessableEntity, "Please fix the given errors", fields)
+		return false
+	}
+
+	SendErrorResponse(ctx, http.StatusBadRequest, "Unable to bind request", err)
+	return false
+}
@@ -0,0 +1,19 @@
+package utility
+
+import "context"
+
+type contextKey string
+
+const userIDContextKey contextKey = "user_id"
+
+// ContextWithUserID returns a copy of ctx carrying the authenticated user's ID
+// so code below the handlers, such as database routing, can see who is acting
+func ContextWithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
+// UserIDFromContext returns the user ID stored by ContextWithUserID
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(int64)
+	return userID, ok && userID != 0
+}
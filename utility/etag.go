@@ -0,0 +1,54 @@
+package utility
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag is the strong entity tag of a record at version
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// SetETag tells the client which version of a record the response holds, for
+// it to send back in If-Match when it changes the record
+func SetETag(ctx *gin.Context, version int64) {
+	ctx.Header("ETag", ETag(version))
+}
+
+// IfMatchVersion reads the version a client expects to change from the
+// If-Match header. When the header is missing or is not an ETag of this API
+// it writes the error response and returns false: 428, 412 for a weak tag,
+// or 400.
+func IfMatchVersion(ctx *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		SendErrorResponse(ctx, http.StatusPreconditionRequired, "If-Match header required", nil)
+		return 0, false
+	}
+
+	// If-Match uses strong comparison (RFC 7232 section 3.1), so a weak tag
+	// never matches and cannot authorize a write
+	if strings.HasPrefix(header, "W/") {
+		SendErrorResponse(ctx, http.StatusPreconditionFailed, "If-Match must be a strong ETag", nil)
+		return 0, false
+	}
+
+	// A list or * cannot say which version the client saw
+	unquoted, err := strconv.Unquote(header)
+	if err != nil || !strings.HasPrefix(header, `"`) {
+		SendErrorResponse(ctx, http.StatusBadRequest, "If-Match must be the ETag of the record", nil)
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		SendErrorResponse(ctx, http.StatusBadRequest, "If-Match must be the ETag of the record", nil)
+		return 0, false
+	}
+
+	return version, true
+}
@@ -10,14 +10,44 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecretKey = []byte("oms_super_secret_key")
+const (
+	// loginSubject marks tokens that back a session
+	loginSubject = "login"
+	// mfaChallengeSubject marks tokens that only let a user send their
+	// second factor after a correct password
+	mfaChallengeSubject = "mfa_challenge"
+)
+
+func GenerateJWT(secret string, userId int64, expirationTime time.Duration) (string, error) {
+	return generateToken(secret, loginSubject, userId, expirationTime)
+}
+
+// GenerateMFAChallenge issues the token a user exchanges, together with a
+// second factor, for a session
+func GenerateMFAChallenge(secret string, userId int64, expirationTime time.Duration) (string, error) {
+	return generateToken(secret, mfaChallengeSubject, userId, expirationTime)
+}
+
+// VerifyMFAChallenge checks a token issued by GenerateMFAChallenge
+func VerifyMFAChallenge(secret, tokenString string) (*types.CustomClaims, error) {
+	claims, err := VerifyJWT(secret, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject != mfaChallengeSubject {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return claims, nil
+}
 
-func GenerateJWT(userId int64, expirationTime time.Duration) (string, error) {
+func generateToken(secret, subject string, userId int64, expirationTime time.Duration) (string, error) {
 	claims := &types.CustomClaims{
 		UserID: userId,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "oms-auth-server",
-			Subject:   "login",
+			Subject:   subject,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expirationTime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ID:        strconv.FormatInt(userId, 10),
@@ -26,7 +56,7 @@ func GenerateJWT(userId int64, expirationTime time.Duration) (string, error) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	tokenString, err := token.SignedString(jwtSecretKey)
+	tokenString, err := token.SignedString([]byte(secret))
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -34,12 +64,12 @@ func GenerateJWT(userId int64, expirationTime time.Duration) (string, error) {
 	return tokenString, nil
 }
 
-func VerifyJWT(tokenString string) (*types.CustomClaims, error) {
+func VerifyJWT(secret, tokenString string) (*types.CustomClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &types.CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return jwtSecretKey, nil
+		return []byte(secret), nil
 	})
 
 	if err != nil {
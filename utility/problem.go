@@ -0,0 +1,107 @@
+package utility
+
+import (
+	"net/http"
+	"oms/i18n"
+	"oms/validation"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details. Clients
+// that list it in Accept before application/json get errors in this format.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBase prefixes the type URI of every problem. The URIs are
+// relative, so they resolve against the API host.
+const ProblemTypeBase = "/problems/"
+
+// problemTypes names the problem type of each status code. Other codes use
+// about:blank, as RFC 7807 suggests when the status says it all.
+var problemTypes = map[int]string{
+	http.StatusBadRequest:           "bad-request",
+	http.StatusUnauthorized:         "unauthorized",
+	http.StatusForbidden:            "forbidden",
+	http.StatusNotFound:             "not-found",
+	http.StatusConflict:             "conflict",
+	http.StatusPreconditionFailed:   "precondition-failed",
+	http.StatusUnprocessableEntity:  "validation-error",
+	http.StatusLocked:               "locked",
+	http.StatusPreconditionRequired: "precondition-required",
+	http.StatusTooManyRequests:      "rate-limited",
+	http.StatusInternalServerError:  "internal-error",
+	http.StatusGatewayTimeout:       "timeout",
+}
+
+// Problem is an RFC 7807 problem details document
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam explains why one request field was rejected
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// WantsProblem reports whether the client asked for problem details
+func WantsProblem(ctx *gin.Context) bool {
+	return ctx.NegotiateFormat(gin.MIMEJSON, ProblemContentType) == ProblemContentType
+}
+
+// SendProblem writes an RFC 7807 response. errs is used the same way as in
+// SendErrorResponse; field errors found in it become invalid_params. The
+// caller translates message.
+func SendProblem(ctx *gin.Context, code int, message string, errs interface{}) {
+	problemType := "about:blank"
+	if name, ok := problemTypes[code]; ok {
+		problemType = ProblemTypeBase + name
+	}
+
+	problem := Problem{
+		Type:          problemType,
+		Title:         i18n.T(Language(ctx), http.StatusText(code)),
+		Status:        code,
+		Detail:        message,
+		Instance:      ctx.Request.URL.Path,
+		InvalidParams: invalidParams(errs, Language(ctx)),
+	}
+
+	// JSON keeps a content type that is already set
+	ctx.Header("Content-Type", ProblemContentType)
+	ctx.JSON(code, problem)
+}
+
+// invalidParams collects the field errors in errs: validation errors, JSON
+// fields of the wrong type, and messages keyed by field name
+func invalidParams(errs interface{}, lang string) []InvalidParam {
+	if err, ok := errs.(error); ok {
+		errs = validation.FieldErrors(err, lang)
+	}
+
+	fields, ok := errs.(map[string][]string)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var params []InvalidParam
+	for _, name := range names {
+		for _, reason := range fields[name] {
+			params = append(params, InvalidParam{Name: name, Reason: reason})
+		}
+	}
+
+	return params
+}
@@ -0,0 +1,126 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"oms/i18n"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldErrors lists the messages in lang for each field rejected in err,
+// keyed by the field's JSON name. It understands validation errors and JSON
+// values of the wrong type, and returns nil for any other error.
+func FieldErrors(err error, lang string) map[string][]string {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make(map[string][]string, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields[fieldErr.Field()] = append(fields[fieldErr.Field()], FieldErrorMessage(fieldErr, lang))
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return map[string][]string{
+			typeErr.Field: {i18n.Sprintf(lang, "The %s must be of type %s.", getFieldDisplayName(typeErr.Field), typeErr.Type.String())},
+		}
+	}
+
+	return nil
+}
+
+// FieldErrorMessage describes a failed validation rule to the client in lang
+func FieldErrorMessage(err validator.FieldError, lang string) string {
+	return getErrorMessage(lang, err.Field(), err.Tag(), err.Param(), err.Kind())
+}
+
+// getErrorMessage returns user-friendly error messages
+func getErrorMessage(lang, fieldName, tag, param string, kind reflect.Kind) string {
+	name := getFieldDisplayName(fieldName)
+
+	switch tag {
+	case "required", "required_without":
+		return i18n.Sprintf(lang, "The %s field is required.", name)
+	case "min":
+		if kind == reflect.String {
+			return i18n.Sprintf(lang, "The %s must be at least %s characters.", name, param)
+		}
+		return i18n.Sprintf(lang, "The %s must be at least %s.", name, param)
+	case "max":
+		if kind == reflect.String {
+			return i18n.Sprintf(lang, "The %s may not be greater than %s characters.", name, param)
+		}
+		return i18n.Sprintf(lang, "The %s may not be greater than %s.", name, param)
+	case "gt":
+		return i18n.Sprintf(lang, "The %s must be greater than %s.", name, param)
+	case "gte":
+		return i18n.Sprintf(lang, "The %s must be greater than or equal to %s.", name, param)
+	case "oneof":
+		return i18n.Sprintf(lang, "The %s must be one of: %s.", name, strings.ReplaceAll(param, " ", ", "))
+	case "email":
+		return i18n.Sprintf(lang, "The %s must be a valid email address.", name)
+	case "bdphone":
+		return i18n.Sprintf(lang, "The %s must be a valid Bangladeshi phone number (01XXXXXXXXX).", name)
+	case "money":
+		return i18n.Sprintf(lang, "The %s must be a non-negative amount with at most %d decimal places.", name, moneyDecimals)
+	case "weight":
+		return i18n.Sprintf(lang, "The %s must be a non-negative weight in kg with at most %d decimal places.", name, weightDecimals)
+	default:
+		return i18n.Sprintf(lang, "The %s field is invalid.", name)
+	}
+}
+
+// FieldDisplayName names a field, given by its struct or JSON name, the way
+// messages to clients do
+func FieldDisplayName(fieldName string) i18n.Text {
+	return getFieldDisplayName(fieldName)
+}
+
+// getFieldDisplayName returns user-friendly field names, which Sprintf
+// translates
+func getFieldDisplayName(fieldName string) i18n.Text {
+	displayNames := map[string]string{
+		"storeid":            "store",
+		"merchantorderid":    "merchant order ID",
+		"recipientname":      "recipient name",
+		"recipientphone":     "recipient phone",
+		"recipientaddress":   "recipient address",
+		"recipientcity":      "recipient city",
+		"recipientzone":      "recipient zone",
+		"recipientarea":      "recipient area",
+		"deliverytype":       "delivery type",
+		"itemtype":           "item type",
+		"itemquantity":       "item quantity",
+		"itemweight":         "item weight",
+		"orderamount":        "order amount",
+		"itemdescription":    "item description",
+		"specialinstruction": "special instruction",
+		"promodiscount":      "promo discount",
+		"discount":           "discount",
+		"userid":             "user ID",
+		"cityid":             "city",
+		"zoneid":             "zone",
+		"basedeliveryfee":    "base delivery fee",
+		"contactphone":       "contact phone",
+		"contactname":        "contact name",
+		"newpassword":        "new password",
+		"currentpassword":    "current password",
+	}
+
+	// Fields may be named by their struct or JSON name
+	if displayName, exists := displayNames[strings.ToLower(strings.ReplaceAll(fieldName, "_", ""))]; exists {
+		return i18n.Text(displayName)
+	}
+
+	return i18n.Text(strings.ReplaceAll(fieldName, "_", " "))
+}
+
+// InvalidValueMessage tells the client in lang that the value given for
+// fieldName could not be read, for errors that carry no rule
+func InvalidValueMessage(fieldName, lang string) string {
+	return getErrorMessage(lang, fieldName, "", "", reflect.Invalid)
+}
@@ -0,0 +1,132 @@
+// Package validation holds the one validator used for every request, with
+// the rules specific to this domain, and turns its errors into messages per
+// field.
+package validation
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// moneyDecimals is the precision of amounts in taka: whole paisa
+	moneyDecimals = 2
+	// weightDecimals is the precision of weights in kg, as stored
+	weightDecimals = 2
+)
+
+// bdPhonePattern matches Bangladeshi mobile numbers such as 01712345678
+var bdPhonePattern = regexp.MustCompile(`^01[3-9][0-9]{8}$`)
+
+var (
+	instance *validator.Validate
+	once     sync.Once
+)
+
+// Validator returns the shared validator. It names fields by their JSON key
+// and knows these rules besides the built-in ones:
+//
+//   - bdphone: a Bangladeshi mobile number, 01XXXXXXXXX
+//   - money: a non-negative amount with at most 2 decimal places
+//   - weight: a non-negative weight in kg with at most 2 decimal places
+func Validator() *validator.Validate {
+	once.Do(func() {
+		validate := validator.New(validator.WithRequiredStructEnabled())
+		validate.RegisterTagNameFunc(jsonFieldName)
+
+		// The rules are fixed and valid, so registering cannot fail
+		_ = validate.RegisterValidation("bdphone", isBDPhone)
+		_ = validate.RegisterValidation("money", isMoney)
+		_ = validate.RegisterValidation("weight", isWeight)
+
+		instance = validate
+	})
+
+	return instance
+}
+
+// Struct validates the fields of a struct, or of the struct s points to
+func Struct(s any) error {
+	return Validator().Struct(s)
+}
+
+// ginValidator lets gin validate bound requests with the shared validator
+type ginValidator struct{}
+
+// NewGinValidator returns a validator to install as binding.Validator, so
+// every ShouldBind call checks the `validate` tags of the request
+func NewGinValidator() binding.StructValidator {
+	return ginValidator{}
+}
+
+func (ginValidator) ValidateStruct(obj any) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return Validator().Struct(obj)
+}
+
+func (ginValidator) Engine() any {
+	return Validator()
+}
+
+// jsonFieldName names struct fields in validation errors by their JSON key,
+// the name clients know them by
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func isBDPhone(fl validator.FieldLevel) bool {
+	return bdPhonePattern.MatchString(fl.Field().String())
+}
+
+func isMoney(fl validator.FieldLevel) bool {
+	return isNonNegativeWithDecimals(fl.Field(), moneyDecimals)
+}
+
+func isWeight(fl validator.FieldLevel) bool {
+	return isNonNegativeWithDecimals(fl.Field(), weightDecimals)
+}
+
+// isNonNegativeWithDecimals checks numbers; integers are in the smallest unit
+// already, so only their sign matters
+func isNonNegativeWithDecimals(field reflect.Value, decimals int) bool {
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		value := field.Float()
+		if value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+			return false
+		}
+		scaled := value * math.Pow10(decimals)
+		// Allow for binary rounding, such as 0.1+0.2 in float64
+		return math.Abs(scaled-math.Round(scaled)) < 1e-6
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int() >= 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}